@@ -0,0 +1,108 @@
+package editor
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// event posted when the editor has been idle for the autosave delay
+type EventAutosave struct {
+	tcell.EventTime
+	id int
+}
+
+type EditorAutosave struct {
+	delay  time.Duration // 0 when the autosave is disabled
+	timer  *time.Timer
+	id     int           // identifies the last started timer, the events of the older ones are dropped
+	cancel chan struct{} // closed to stop posting the events
+}
+
+// get the autosave delay given in the configuration (in seconds)
+func parseAutosaveDelay(seconds string) (time.Duration, error) {
+	n, err := strconv.Atoi(seconds)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid autosave delay %q, expected a number of seconds", seconds)
+	}
+
+	return time.Duration(n) * time.Second, nil
+}
+
+// start again the wait for the editor to be idle, the modified documents are saved once it ends
+func (e *Editor) restartAutosaveTimer() {
+	if e.autosave.delay == 0 {
+		return
+	}
+
+	if e.autosave.cancel == nil {
+		e.autosave.cancel = make(chan struct{})
+	}
+
+	if e.autosave.timer != nil {
+		e.autosave.timer.Stop()
+	}
+
+	e.autosave.id++
+
+	id := e.autosave.id
+	screen := e.screen
+	cancel := e.autosave.cancel
+	e.autosave.timer = time.AfterFunc(e.autosave.delay, func() {
+		ev := &EventAutosave{id: id}
+		ev.SetEventNow()
+		postEvent(screen, ev, cancel)
+	})
+}
+
+func (e *Editor) stopAutosave() {
+	if e.autosave.timer != nil {
+		e.autosave.timer.Stop()
+		e.autosave.timer = nil
+	}
+
+	if e.autosave.cancel != nil {
+		close(e.autosave.cancel)
+		e.autosave.cancel = nil
+	}
+}
+
+// save the modified documents that have a file (the other ones wait to be named)
+func (e *Editor) autosaveDocuments() {
+	for _, doc := range e.getModifiedDocuments() {
+		if doc.path == "" {
+			continue
+		}
+
+		err := e.saveDocument(doc)
+		if err != nil {
+			e.setMessage("autosave: %s", err)
+		}
+	}
+}
+
+// handle the events of the autosave, they must not change what is shown
+func (e *Editor) handleAutosaveEvent(ev tcell.Event) bool {
+	if e.autosave.delay == 0 {
+		return false
+	}
+
+	switch ev := ev.(type) {
+	case *EventAutosave:
+		if ev.id == e.autosave.id {
+			e.autosaveDocuments()
+		}
+		return true
+	case *tcell.EventFocus:
+		if !ev.Focused {
+			e.autosaveDocuments()
+		}
+		return true
+	case *tcell.EventKey, *tcell.EventMouse, *tcell.EventPaste:
+		e.restartAutosaveTimer()
+	}
+
+	return false
+}
@@ -1,6 +1,10 @@
 package editor
 
-import "fmt"
+import (
+    "fmt"
+    "strings"
+    "unicode"
+)
 
 const (
     BUFFER_INITIAL_CAPACITY = 1
@@ -8,7 +12,8 @@ const (
 )
 
 type Buffer struct {
-    lines []Line
+    lines    []Line
+    modified bool // the content changed since it was loaded (or saved)
 }
 
 func newBuffer() Buffer {
@@ -38,7 +43,13 @@ func (buffer *Buffer) insertString(s string, cursor *Location) error {
         return fmt.Errorf("[BUFFER ERROR] invalid cursor position, failed to append string")
     }
 
-    return buffer.lines[cursor.getLine()].insertString(s, cursor)
+    err := buffer.lines[cursor.getLine()].insertString(s, cursor)
+    if err != nil {
+        return err
+    }
+
+    buffer.modified = true
+    return nil
 }
 
 func getComplementaryChar(c rune) (comc rune, hasOne bool) {
@@ -98,12 +109,14 @@ func (buffer *Buffer) removeLine(lineIndex int) error {
     }
 
     buffer.lines = lines
+    buffer.modified = true
 
     return nil
 }
 
 func (buffer *Buffer) appendLineContent(lineIndex, lineIndexToAppend int) {
     buffer.lines[lineIndex].content += buffer.lines[lineIndexToAppend].content
+    buffer.modified = true
 }
 
 func (buffer *Buffer) removeString(count int, cursor *Location) error {
@@ -134,12 +147,20 @@ func (buffer *Buffer) removeString(count int, cursor *Location) error {
         return err
     }
 
+    if charscount > 0 {
+        buffer.modified = true
+    }
+
     count -= charscount
 
     switch {
     case buffer.isEmpty():
         return nil
 
+    // the start of the file is reached before removing everything
+    case cursor.getLine() == 0:
+        return nil
+
     case count >= 1:
         prevLinecount := buffer.lines[cursor.getLine()-1].count()
 
@@ -218,6 +239,7 @@ func (buffer *Buffer) insertNewLine(cursor *Location) error {
     }
 
     buffer.lines = lines
+    buffer.modified = true
 
     cursor.setLine(cursor.getLine() + 1)
     cursor.setCol(0)
@@ -255,5 +277,119 @@ func (buffer *Buffer) findAndReplace(newText, prevText string, location *Locatio
         return
     }
     buffer.lines[location.getLine()].replace(location, prevText, newText)
+    buffer.modified = true
+}
+
+
+// get the locations of 'text' that lie entirely between 'start' and 'end' (end not included)
+func (buffer *Buffer) searchInRange(text string, start, end Location) []Location {
+    var locations []Location
+
+    for _, loc := range buffer.search(text) {
+        if loc.isBefore(start) {
+            continue
+        }
+
+        matchEnd := newLocation(loc.getLine(), loc.getCol()+len(text))
+        if end.isBefore(matchEnd) {
+            continue
+        }
+
+        locations = append(locations, loc)
+    }
+
+    return locations
+}
+
+func isWordChar(c byte) bool {
+    return c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// get the columns of the start and of the end (not included) of the word the cursor is on (or right after)
+func (buffer *Buffer) wordBoundsAt(cursor Location) (int, int) {
+    if !buffer.isValidLine(cursor.getLine()) {
+        return 0, 0
+    }
+
+    content := buffer.lines[cursor.getLine()].content
+    start, end := cursor.getCol(), cursor.getCol()
+    if end > len(content) {
+        return 0, 0
+    }
+
+    for start > 0 && isWordChar(content[start-1]) {
+        start--
+    }
+
+    for end < len(content) && isWordChar(content[end]) {
+        end++
+    }
+
+    return start, end
+}
+
+// get the word the cursor is on (or right after)
+func (buffer *Buffer) wordAt(cursor Location) string {
+    start, end := buffer.wordBoundsAt(cursor)
+    if start == end {
+        return ""
+    }
+
+    return buffer.lines[cursor.getLine()].content[start:end]
 }
 
+// get the text between 'start' and 'end' (end not included), lines are joined with '\n'
+func (buffer *Buffer) getText(start, end Location) string {
+    start, end = sortLocations(start, end)
+    if !buffer.isValidLine(end.getLine()) {
+        return ""
+    }
+
+    if start.getLine() == end.getLine() {
+        return buffer.lines[start.getLine()].content[start.getCol():end.getCol()]
+    }
+
+    var builder strings.Builder
+    builder.WriteString(buffer.lines[start.getLine()].content[start.getCol():])
+    for i := start.getLine() + 1; i < end.getLine(); i++ {
+        builder.WriteString("\n")
+        builder.WriteString(buffer.lines[i].content)
+    }
+    builder.WriteString("\n")
+    builder.WriteString(buffer.lines[end.getLine()].content[:end.getCol()])
+
+    return builder.String()
+}
+
+// replace the content of the buffer with the content of a file, the tabs are expanded like when they are typed
+func (buffer *Buffer) load(content []byte) error {
+    lines := strings.Split(string(content), "\n")
+
+    buffer.lines = make([]Line, 0, len(lines))
+    for _, line := range lines {
+        buffer.lines = append(buffer.lines, newLine(expandTabs(line)))
+    }
+
+    buffer.modified = false
+    return nil
+}
+
+// get the nearest location of the buffer to 'loc' (the buffer may have been changed since it was taken)
+func (buffer *Buffer) clampLocation(loc Location) Location {
+    line := min(max(loc.getLine(), 0), buffer.count()-1)
+    col := min(max(loc.getCol(), 0), buffer.lines[line].count())
+
+    return newLocation(line, col)
+}
+
+// append the content read from a file at the end of the buffer, the buffer does not become modified
+func (buffer *Buffer) appendContent(content []byte) {
+    lines := strings.Split(string(content), "\n")
+
+    last := &buffer.lines[buffer.count()-1]
+    last.content += expandTabs(lines[0])
+
+    for _, line := range lines[1:] {
+        buffer.lines = append(buffer.lines, newLine(expandTabs(line)))
+    }
+}
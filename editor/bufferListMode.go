@@ -0,0 +1,67 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// open the list of the opened buffers over the text, the current one is selected
+func (e *Editor) setBufferListMode() {
+	e.bufferListParams.current = e.currentDocument
+	e.mode = BUFFER_LIST_MODE
+}
+
+func (e *Editor) switchToInsertFromBufferListMode() {
+	e.mode = INSERT_MODE
+}
+
+func (e *Editor) updateBufferListIndexCursorDown() {
+	e.bufferListParams.current = (e.bufferListParams.current + 1) % len(e.documents)
+}
+
+func (e *Editor) updateBufferListIndexCursorUp() {
+	e.bufferListParams.current = (e.bufferListParams.current + len(e.documents) - 1) % len(e.documents)
+}
+
+// get the text of a document in the list: modified marker, name, then directory
+func (doc *Document) bufferListText() string {
+	marker := "   "
+	if doc.buffer.modified {
+		marker = "[+]"
+	}
+
+	if doc.path == "" {
+		return fmt.Sprintf("%s %s", marker, doc.getName())
+	}
+
+	return fmt.Sprintf("%s %s  %s", marker, doc.getName(), filepath.Dir(doc.path))
+}
+
+// handle the keys of the list of the opened buffers
+func (e *Editor) handleBufferListModeEvent(ev tcell.Event) error {
+	evKey, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return nil
+	}
+
+	// the selected buffer may have been closed since the last key
+	e.bufferListParams.current = min(e.bufferListParams.current, len(e.documents)-1)
+
+	switch evKey.Key() {
+	case tcell.KeyEscape:
+		e.switchToInsertFromBufferListMode()
+	case tcell.KeyEnter:
+		e.switchToDocument(e.bufferListParams.current)
+		e.switchToInsertFromBufferListMode()
+	case tcell.KeyDown:
+		e.updateBufferListIndexCursorDown()
+	case tcell.KeyUp:
+		e.updateBufferListIndexCursorUp()
+	case tcell.KeyDelete, tcell.KeyCtrlW:
+		e.closeDocument(e.bufferListParams.current)
+	}
+
+	return nil
+}
@@ -0,0 +1,407 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+// get a buffer holding the lines of 'text' (separated by '\n')
+func newBufferFromText(text string) *Buffer {
+	buffer := newBuffer()
+	buffer.load([]byte(text))
+	return &buffer
+}
+
+func bufferText(buffer *Buffer) string {
+	return string(buffer.saveContent())
+}
+
+func TestBufferRemoveString(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		cursor     Location
+		count      int
+		want       string
+		wantCursor Location
+	}{
+		{name: "start of the file", text: "ab\ncd", cursor: newLocation(0, 0), count: 1, want: "ab\ncd", wantCursor: newLocation(0, 0)},
+		{name: "empty buffer", text: "", cursor: newLocation(0, 0), count: 3, want: "", wantCursor: newLocation(0, 0)},
+		{name: "nothing", text: "ab", cursor: newLocation(0, 1), count: 0, want: "ab", wantCursor: newLocation(0, 1)},
+		{name: "one char", text: "abc", cursor: newLocation(0, 2), count: 1, want: "ac", wantCursor: newLocation(0, 1)},
+		{name: "more than the file", text: "abc", cursor: newLocation(0, 2), count: 5, want: "c", wantCursor: newLocation(0, 0)},
+		{name: "start of a line", text: "ab\ncd", cursor: newLocation(1, 0), count: 1, want: "abcd", wantCursor: newLocation(0, 2)},
+		{name: "across a line", text: "ab\ncd", cursor: newLocation(1, 1), count: 3, want: "ad", wantCursor: newLocation(0, 1)},
+		{name: "across empty lines", text: "a\n\n\nb", cursor: newLocation(3, 0), count: 3, want: "ab", wantCursor: newLocation(0, 1)},
+		{name: "whole file", text: "ab\ncd", cursor: newLocation(1, 2), count: 5, want: "", wantCursor: newLocation(0, 0)},
+		{name: "empty line at the start", text: "\nab", cursor: newLocation(1, 0), count: 1, want: "ab", wantCursor: newLocation(0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newBufferFromText(tt.text)
+			cursor := tt.cursor
+
+			err := buffer.removeString(tt.count, &cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := bufferText(buffer); got != tt.want || cursor != tt.wantCursor {
+				t.Errorf("got %q with the cursor at %v, expected %q at %v", got, cursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestBufferRemoveChar(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		cursor     Location
+		want       string
+		wantCursor Location
+	}{
+		{name: "start of the file", text: "ab", cursor: newLocation(0, 0), want: "ab", wantCursor: newLocation(0, 0)},
+		{name: "one char", text: "ab", cursor: newLocation(0, 2), want: "a", wantCursor: newLocation(0, 1)},
+		{name: "start of a line", text: "a\nb", cursor: newLocation(1, 0), want: "ab", wantCursor: newLocation(0, 1)},
+		{name: "empty line", text: "a\n\nb", cursor: newLocation(1, 0), want: "a\nb", wantCursor: newLocation(0, 1)},
+		{name: "tab", text: "    a", cursor: newLocation(0, 4), want: "a", wantCursor: newLocation(0, 0)},
+		{name: "tab after a tab", text: "        a", cursor: newLocation(0, 8), want: "    a", wantCursor: newLocation(0, 4)},
+		{name: "tab not on a tab stop", text: "a     b", cursor: newLocation(0, 6), want: "a b", wantCursor: newLocation(0, 2)},
+		{name: "less spaces than a tab", text: "a   b", cursor: newLocation(0, 4), want: "a  b", wantCursor: newLocation(0, 3)},
+		{name: "spaces at the start", text: "   a", cursor: newLocation(0, 3), want: "  a", wantCursor: newLocation(0, 2)},
+		{name: "matching chars", text: "()", cursor: newLocation(0, 1), want: "", wantCursor: newLocation(0, 0)},
+		{name: "matching chars after a tab", text: "    []", cursor: newLocation(0, 5), want: "    ", wantCursor: newLocation(0, 4)},
+		{name: "matching quotes", text: "a\"\"", cursor: newLocation(0, 2), want: "a", wantCursor: newLocation(0, 1)},
+		{name: "not matching chars", text: "(]", cursor: newLocation(0, 1), want: "]", wantCursor: newLocation(0, 0)},
+		{name: "closing char", text: "()", cursor: newLocation(0, 2), want: "(", wantCursor: newLocation(0, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newBufferFromText(tt.text)
+			cursor := tt.cursor
+
+			err := buffer.removeChar(&cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := bufferText(buffer); got != tt.want || cursor != tt.wantCursor {
+				t.Errorf("got %q with the cursor at %v, expected %q at %v", got, cursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestBufferHasMatchingChars(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		cursor Location
+		want   bool
+	}{
+		{name: "empty line", text: "", cursor: newLocation(0, 0), want: false},
+		{name: "start of the line", text: "()", cursor: newLocation(0, 0), want: false},
+		{name: "between the chars", text: "()", cursor: newLocation(0, 1), want: true},
+		{name: "end of the line", text: "()", cursor: newLocation(0, 2), want: false},
+		{name: "brackets", text: "a[]", cursor: newLocation(0, 2), want: true},
+		{name: "braces", text: "{}", cursor: newLocation(0, 1), want: true},
+		{name: "angle brackets", text: "<>", cursor: newLocation(0, 1), want: true},
+		{name: "quotes", text: "''", cursor: newLocation(0, 1), want: true},
+		{name: "other chars", text: "ab", cursor: newLocation(0, 1), want: false},
+		{name: "reversed chars", text: ")(", cursor: newLocation(0, 1), want: false},
+		{name: "not matching chars", text: "(}", cursor: newLocation(0, 1), want: false},
+		{name: "second line", text: "a\n()", cursor: newLocation(1, 1), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newBufferFromText(tt.text)
+			cursor := tt.cursor
+
+			if got := buffer.hasMatchingChars(&cursor); got != tt.want {
+				t.Errorf("got %t, expected %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBufferInsertChar(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		cursor     Location
+		c          rune
+		want       string
+		wantCursor Location
+	}{
+		{name: "char", text: "ac", cursor: newLocation(0, 1), c: 'b', want: "abc", wantCursor: newLocation(0, 2)},
+		{name: "opening char", text: "a", cursor: newLocation(0, 1), c: '(', want: "a()", wantCursor: newLocation(0, 2)},
+		{name: "quote", text: "", cursor: newLocation(0, 0), c: '"', want: "\"\"", wantCursor: newLocation(0, 1)},
+		{name: "closing char", text: "", cursor: newLocation(0, 0), c: ')', want: ")", wantCursor: newLocation(0, 1)},
+		{name: "second line", text: "a\nb", cursor: newLocation(1, 0), c: 'x', want: "a\nxb", wantCursor: newLocation(1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newBufferFromText(tt.text)
+			cursor := tt.cursor
+
+			err := buffer.insertChar(tt.c, &cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := bufferText(buffer); got != tt.want || cursor != tt.wantCursor {
+				t.Errorf("got %q with the cursor at %v, expected %q at %v", got, cursor, tt.want, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestBufferInsertNewLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		cursor Location
+		want   string
+	}{
+		{name: "empty buffer", text: "", cursor: newLocation(0, 0), want: "\n"},
+		{name: "start of a line", text: "ab", cursor: newLocation(0, 0), want: "\nab"},
+		{name: "middle of a line", text: "ab", cursor: newLocation(0, 1), want: "a\nb"},
+		{name: "end of a line", text: "ab\ncd", cursor: newLocation(0, 2), want: "ab\n\ncd"},
+		{name: "last line", text: "ab\ncd", cursor: newLocation(1, 2), want: "ab\ncd\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newBufferFromText(tt.text)
+			cursor := tt.cursor
+
+			err := buffer.insertNewLine(&cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantCursor := newLocation(tt.cursor.getLine()+1, 0)
+			if got := bufferText(buffer); got != tt.want || cursor != wantCursor {
+				t.Errorf("got %q with the cursor at %v, expected %q at %v", got, cursor, tt.want, wantCursor)
+			}
+		})
+	}
+}
+
+func TestBufferLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLines []string
+	}{
+		{name: "empty file", content: "", wantLines: []string{""}},
+		{name: "one line", content: "ab", wantLines: []string{"ab"}},
+		{name: "final new line", content: "ab\n", wantLines: []string{"ab", ""}},
+		{name: "empty lines", content: "\n\n", wantLines: []string{"", "", ""}},
+		{name: "tabs", content: "\ta\tb", wantLines: []string{"    a    b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := newBufferFromText(tt.content)
+
+			var lines []string
+			for _, line := range buffer.lines {
+				lines = append(lines, line.content)
+			}
+
+			if strings.Join(lines, "|") != strings.Join(tt.wantLines, "|") {
+				t.Errorf("got the lines %q, expected %q", lines, tt.wantLines)
+			}
+
+			if want := expandTabs(tt.content); bufferText(buffer) != want {
+				t.Errorf("saved %q, expected %q", bufferText(buffer), want)
+			}
+		})
+	}
+}
+
+func TestBufferClampLocation(t *testing.T) {
+	buffer := newBufferFromText("abc\nd")
+
+	tests := []struct {
+		loc  Location
+		want Location
+	}{
+		{loc: newLocation(0, 2), want: newLocation(0, 2)},
+		{loc: newLocation(1, 5), want: newLocation(1, 1)},
+		{loc: newLocation(7, 0), want: newLocation(1, 0)},
+		{loc: newLocation(-1, -1), want: newLocation(0, 0)},
+	}
+
+	for _, tt := range tests {
+		if got := buffer.clampLocation(tt.loc); got != tt.want {
+			t.Errorf("%v clamped to %v, expected %v", tt.loc, got, tt.want)
+		}
+	}
+}
+
+// a reference model of the buffer: its text with the lines joined by '\n', the cursor is an offset in it
+type bufferModel struct {
+	text   string
+	cursor int
+}
+
+var modelPairs = map[byte]byte{'{': '}', '(': ')', '[': ']', '"': '"', '\'': '\'', '<': '>'}
+
+func (m *bufferModel) lineStart() int {
+	return strings.LastIndexByte(m.text[:m.cursor], '\n') + 1
+}
+
+func (m *bufferModel) location() Location {
+	return newLocation(strings.Count(m.text[:m.cursor], "\n"), m.cursor-m.lineStart())
+}
+
+func (m *bufferModel) insert(s string) {
+	m.text = m.text[:m.cursor] + s + m.text[m.cursor:]
+	m.cursor += len(s)
+}
+
+func (m *bufferModel) insertChar(c byte) {
+	if closing, ok := modelPairs[c]; ok {
+		m.insert(string([]byte{c, closing}))
+		m.cursor--
+		return
+	}
+
+	m.insert(string(c))
+}
+
+func (m *bufferModel) remove(count int) {
+	count = min(count, m.cursor)
+	m.text = m.text[:m.cursor-count] + m.text[m.cursor:]
+	m.cursor -= count
+}
+
+// remove a tab when the cursor is after one, both chars of a pair when it is between them, one char otherwise
+func (m *bufferModel) removeChar() {
+	start := m.lineStart()
+	col := m.cursor - start
+
+	if col >= BUFFER_TAB_SIZE && m.text[m.cursor-BUFFER_TAB_SIZE:m.cursor] == strings.Repeat(" ", BUFFER_TAB_SIZE) {
+		m.remove(BUFFER_TAB_SIZE)
+		return
+	}
+
+	if col > 0 && m.cursor < len(m.text) {
+		if closing, ok := modelPairs[m.text[m.cursor-1]]; ok && closing == m.text[m.cursor] {
+			m.cursor++
+			m.remove(2)
+			return
+		}
+	}
+
+	m.remove(1)
+}
+
+const (
+	FUZZ_ALPHABET       = "ab (){}[]<>\"'"
+	FUZZ_MAX_OPERATIONS = 256 // the longer inputs only slow the fuzzing down
+)
+
+// apply the operations encoded in 'ops' (an operation and its argument by pair of bytes) to a buffer and to the
+// reference model, the buffer must stay the same as the model after each one
+func FuzzBufferOperations(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 3, 0})
+	f.Add([]byte{2, 0, 2, 0, 3, 0, 3, 0})
+	f.Add([]byte{0, 3, 3, 0, 0, 0, 1, 0, 5, 1, 4, 7})
+	f.Add([]byte{6, 4, 1, 0, 6, 2, 5, 3, 3, 0, 4, 2, 1, 0, 1, 0, 5, 0, 3, 0})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		buffer := newBuffer()
+		cursor := newLocation(0, 0)
+		model := &bufferModel{}
+
+		if len(ops) > 2*FUZZ_MAX_OPERATIONS {
+			ops = ops[:2*FUZZ_MAX_OPERATIONS]
+		}
+
+		for i := 0; i+1 < len(ops); i += 2 {
+			op, arg := ops[i], int(ops[i+1])
+
+			var err error
+			switch op % 7 {
+			case 0:
+				c := FUZZ_ALPHABET[arg%len(FUZZ_ALPHABET)]
+				err = buffer.insertChar(rune(c), &cursor)
+				model.insertChar(c)
+			case 1:
+				err = buffer.insertNewLine(&cursor)
+				model.insert("\n")
+			case 2:
+				err = buffer.insertTab(&cursor)
+				model.insert(strings.Repeat(" ", BUFFER_TAB_SIZE))
+			case 3:
+				err = buffer.removeChar(&cursor)
+				model.removeChar()
+			case 4:
+				err = buffer.removeString(arg%8, &cursor)
+				model.remove(arg % 8)
+			case 5:
+				model.cursor = arg % (len(model.text) + 1)
+				cursor = model.location()
+			case 6:
+				s := FUZZ_ALPHABET[:arg%len(FUZZ_ALPHABET)]
+				err = buffer.insertString(s, &cursor)
+				model.insert(s)
+			}
+
+			if err != nil {
+				t.Fatalf("operation %d (%d, %d): %s", i/2, op%7, arg, err)
+			}
+
+			if got := bufferText(&buffer); got != model.text {
+				t.Fatalf("operation %d (%d, %d): the buffer is %q, expected %q", i/2, op%7, arg, got, model.text)
+			}
+
+			if want := model.location(); cursor != want {
+				t.Fatalf("operation %d (%d, %d): the cursor is at %v, expected %v", i/2, op%7, arg, cursor, want)
+			}
+		}
+	})
+}
+
+// search a text in a buffer then replace it, every match must be at its location and the replacement must be the
+// one of strings.ReplaceAll on each line
+func FuzzBufferSearchReplace(f *testing.F) {
+	f.Add("abcabc\nbca", "bc", "x")
+	f.Add("aaaa\n\naa", "aa", "aaa")
+	f.Add("a\tb", "  b", "")
+
+	f.Fuzz(func(t *testing.T, content, text, newText string) {
+		if text == "" || strings.Contains(text, "\n") || strings.Contains(newText, "\n") {
+			return
+		}
+
+		buffer := newBufferFromText(content)
+
+		for _, loc := range buffer.search(text) {
+			line := buffer.lines[loc.getLine()].content
+			if loc.getCol()+len(text) > len(line) || line[loc.getCol():loc.getCol()+len(text)] != text {
+				t.Fatalf("%q is not at %v in %q", text, loc, line)
+			}
+		}
+
+		var want []string
+		for _, line := range buffer.lines {
+			want = append(want, strings.ReplaceAll(line.content, text, newText))
+		}
+
+		editor := &Editor{buffer: buffer}
+		editor.replaceAllText(text, newText)
+
+		if got := bufferText(buffer); got != strings.Join(want, "\n") {
+			t.Fatalf("got %q, expected %q", got, strings.Join(want, "\n"))
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the commands below only change the buffer and the cursor of the current document, they need no screen:
+// the scripts run without a terminal use them, and so do the keys of the editor doing the same things
+
+// move the cursor to the line 'line' and the column 'col' (both start at 0)
+func (editor *Editor) gotoLocation(line, col int) error {
+	if line < 0 || line >= editor.buffer.count() {
+		return fmt.Errorf("line %d is out of the buffer (%d lines)", line+1, editor.buffer.count())
+	}
+
+	if col < 0 || col > editor.buffer.lines[line].count() {
+		return fmt.Errorf("column %d is out of the line %d", col+1, line+1)
+	}
+
+	editor.realCursor = newLocation(line, col)
+	return nil
+}
+
+// move the cursor to the end of the first match of 'text' starting at the cursor or after it
+func (editor *Editor) searchForward(text string) error {
+	if text == "" {
+		return fmt.Errorf("nothing to search")
+	}
+
+	for _, loc := range editor.buffer.search(text) {
+		if loc.isBefore(editor.realCursor) {
+			continue
+		}
+
+		editor.gotoMatchEnd(loc, text)
+		return nil
+	}
+
+	return fmt.Errorf("%q not found", text)
+}
+
+// move the cursor to the end of the match of 'text' at 'loc'
+func (editor *Editor) gotoMatchEnd(loc Location, text string) {
+	editor.realCursor = newLocation(loc.getLine(), loc.getCol()+len(text))
+}
+
+// replace every match of 'oldText' in the buffer with 'newText', it returns the number of replacements
+func (editor *Editor) replaceAllText(oldText, newText string) int {
+	if oldText == "" {
+		return 0
+	}
+
+	locations := nonOverlappingLocations(editor.buffer.search(oldText), len(oldText))
+	editor.replaceLocations(locations, oldText, newText)
+
+	editor.realCursor = editor.buffer.clampLocation(editor.realCursor)
+	return len(locations)
+}
+
+// replace the matches of 'oldText' at 'locations' (in order, not overlapping) with 'newText'
+func (editor *Editor) replaceLocations(locations []Location, oldText, newText string) {
+	// from the last match so the ones before it do not move
+	for i := len(locations) - 1; i >= 0; i-- {
+		loc := locations[i]
+		editor.buffer.findAndReplace(newText, oldText, &loc)
+	}
+}
+
+// insert the text at the cursor, the cursor ends after it
+func (editor *Editor) insertText(text string) error {
+	for i, line := range strings.Split(text, "\n") {
+		if i != 0 {
+			err := editor.buffer.insertNewLine(&editor.realCursor)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := editor.buffer.insertString(expandTabs(line), &editor.realCursor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove the line of the cursor, the cursor goes to the beginning of the line that takes its place
+func (editor *Editor) deleteLine() error {
+	line := editor.realCursor.getLine()
+
+	// the buffer always keeps a line
+	if editor.buffer.count() == 1 {
+		editor.buffer.lines[0] = newLine("")
+		editor.buffer.modified = true
+	} else {
+		err := editor.buffer.removeLine(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	editor.realCursor = editor.buffer.clampLocation(newLocation(line, 0))
+	return nil
+}
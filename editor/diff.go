@@ -0,0 +1,100 @@
+package editor
+
+const (
+	DIFF_CONTEXT_LINES = 3
+	DIFF_MAX_CELLS     = 4000000 // above it the lines are not matched, the whole text is replaced
+)
+
+const (
+	DIFF_SAME = iota
+	DIFF_REMOVED
+	DIFF_ADDED
+)
+
+var diffPrefixes = [...]string{
+	DIFF_SAME:    "  ",
+	DIFF_REMOVED: "- ",
+	DIFF_ADDED:   "+ ",
+}
+
+type DiffLine struct {
+	kind int
+	text string
+}
+
+// get the changes turning the lines 'a' into the lines 'b' (longest common subsequence of the lines)
+func diffLines(a, b []string) []DiffLine {
+	var diff []DiffLine
+
+	if len(a)*len(b) > DIFF_MAX_CELLS {
+		for _, line := range a {
+			diff = append(diff, DiffLine{DIFF_REMOVED, line})
+		}
+		for _, line := range b {
+			diff = append(diff, DiffLine{DIFF_ADDED, line})
+		}
+		return diff
+	}
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int32, len(a)+1)
+	for i := range common {
+		common[i] = make([]int32, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff = append(diff, DiffLine{DIFF_SAME, a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			diff = append(diff, DiffLine{DIFF_REMOVED, a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{DIFF_ADDED, b[j]})
+			j++
+		}
+	}
+
+	return diff
+}
+
+// get the text of the diff, the unchanged lines far from any change are replaced by a "..." line
+func diffText(diff []DiffLine) []string {
+	near := make([]bool, len(diff))
+	for i, line := range diff {
+		if line.kind == DIFF_SAME {
+			continue
+		}
+
+		for j := max(i-DIFF_CONTEXT_LINES, 0); j <= min(i+DIFF_CONTEXT_LINES, len(diff)-1); j++ {
+			near[j] = true
+		}
+	}
+
+	var text []string
+	for i, line := range diff {
+		if near[i] {
+			text = append(text, diffPrefixes[line.kind]+line.text)
+			continue
+		}
+
+		if i == 0 || near[i-1] {
+			text = append(text, "  ...")
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,310 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// a buffer opened in the editor, it keeps the cursor of the editor while another one is the current one
+type Document struct {
+	path             string // empty until the buffer is saved
+	name             string // name of a buffer that is not a file
+	buffer           *Buffer
+	realCursor       Location
+	renderingCursor  Location
+	swapText         []byte    // text written in the swap file, nil when there is none
+	diskState        FileState // the file as it was loaded or saved
+	ignoredDiskState FileState // change of the file the user chose not to reload
+	reloadAsked      bool
+	following        bool       // the content appended to the file is loaded as it comes
+	followOffset     int64      // size of the file already loaded while following it
+	undo             []UndoStep // contents before the last changes, the last one is undone first
+	redo             []UndoStep // contents undone, a new change drops them
+	typing           bool       // the last step is a typing, the chars typed at typingEnd are undone with it
+	typingEnd        Location
+}
+
+func newDocument(path string) *Document {
+	buffer := newBuffer()
+
+	return &Document{
+		path:   path,
+		buffer: &buffer,
+	}
+}
+
+// get the name of the document shown to the user
+func (doc *Document) getName() string {
+	if doc.name != "" {
+		return doc.name
+	}
+
+	if doc.path == "" {
+		return "[no name]"
+	}
+
+	return filepath.Base(doc.path)
+}
+
+// check if the document can be replaced by another one without losing anything
+func (doc *Document) isScratch() bool {
+	return doc.path == "" && doc.name == "" && !doc.buffer.modified && doc.buffer.isEmpty()
+}
+
+// check if the document can not be changed
+func (editor *Editor) isReadOnly(doc *Document) bool {
+	return editor.config.ReadOnly || doc.following
+}
+
+// check that the current document can be changed, the message tells why when it can not
+func (editor *Editor) canEdit() bool {
+	doc := editor.getCurrentDocument()
+	if !editor.isReadOnly(doc) {
+		return true
+	}
+
+	if editor.config.ReadOnly {
+		editor.setMessage("%s is read-only", doc.getName())
+	} else {
+		editor.setMessage("%s is followed, stop following it with Alt+F to change it", doc.getName())
+	}
+
+	return false
+}
+
+// get why the files can not be changed, nil when they can
+func (editor *Editor) getFilesChangeError() error {
+	if editor.config.ReadOnly {
+		return fmt.Errorf("the editor is read-only")
+	}
+
+	// a replay without a terminal only shows what the recording does, it must not change the files of the user
+	if editor.config.Headless {
+		return fmt.Errorf("the files are not changed by a headless replay")
+	}
+
+	return nil
+}
+
+// check that the files can be changed, the message tells why when they can not
+func (editor *Editor) canChangeFiles() bool {
+	err := editor.getFilesChangeError()
+	if err != nil {
+		editor.setMessage("%s", err)
+		return false
+	}
+
+	return true
+}
+
+func (editor *Editor) getCurrentDocument() *Document {
+	return editor.documents[editor.currentDocument]
+}
+
+// find the document of the file at 'path', -1 if it is not opened
+func (editor *Editor) findDocument(path string) int {
+	for i, doc := range editor.documents {
+		if doc.path != "" && doc.path == path {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// keep the cursor of the editor in the current document
+func (editor *Editor) storeCursorInDocument() {
+	current := editor.getCurrentDocument()
+	current.realCursor = editor.realCursor
+	current.renderingCursor = editor.renderingCursor
+}
+
+// make the document at 'index' the current one, the cursor of the editor is replaced by its own
+func (editor *Editor) showDocument(index int) {
+	doc := editor.documents[index]
+	editor.currentDocument = index
+	editor.window.document = doc
+	editor.buffer = doc.buffer
+	editor.realCursor = doc.buffer.clampLocation(doc.realCursor)
+	editor.renderingCursor = doc.renderingCursor
+	editor.config.CurrentFile = doc.path
+}
+
+// make the document at 'index' the current one, the cursor of the previous one is kept in it
+func (editor *Editor) switchToDocument(index int) {
+	if index < 0 || index >= len(editor.documents) {
+		return
+	}
+
+	editor.storeCursorInDocument()
+	editor.showDocument(index)
+}
+
+// add a document and make it the current one, it takes the place of the current one if it is an empty unnamed buffer
+func (editor *Editor) addDocument(doc *Document) {
+	if scratch := editor.getCurrentDocument(); scratch.isScratch() {
+		editor.documents[editor.currentDocument] = doc
+		editor.replaceDocumentInWindows(scratch, doc)
+		editor.showDocument(editor.currentDocument)
+		return
+	}
+
+	editor.documents = append(editor.documents, doc)
+	editor.switchToDocument(len(editor.documents) - 1)
+}
+
+func (editor *Editor) findDocumentByPointer(doc *Document) int {
+	for i, d := range editor.documents {
+		if d == doc {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// set the file the current document is saved into
+func (editor *Editor) setCurrentFile(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	doc := editor.getCurrentDocument()
+	if doc.path != path {
+		editor.removeSwapFile(doc)
+		doc.diskState = FileState{}
+	}
+
+	editor.config.CurrentFile = path
+	doc.path = path
+	editor.watchDocument(doc)
+}
+
+// save the current document, it must have a file name
+func (editor *Editor) saveCurrentDocument() error {
+	if editor.config.CurrentFile == "" {
+		return fmt.Errorf("the buffer has no file name, save it with Ctrl+S first")
+	}
+
+	return editor.save()
+}
+
+func (editor *Editor) getModifiedDocuments() []*Document {
+	var modified []*Document
+	for _, doc := range editor.documents {
+		if doc.buffer.modified {
+			modified = append(modified, doc)
+		}
+	}
+
+	return modified
+}
+
+// save the documents, stopping at the first one that can not be saved (it becomes the current one)
+func (editor *Editor) saveDocuments(documents []*Document) bool {
+	for _, doc := range documents {
+		var err error
+		if doc.path == "" {
+			err = fmt.Errorf("%s has no file name, save it with Ctrl+S first", doc.getName())
+		} else {
+			err = editor.saveDocument(doc)
+		}
+
+		if err != nil {
+			editor.switchToDocument(editor.findDocumentByPointer(doc))
+			editor.setMessage("%s", err)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (editor *Editor) switchToNextDocument() {
+	editor.switchToDocument((editor.currentDocument + 1) % len(editor.documents))
+}
+
+func (editor *Editor) switchToPrevDocument() {
+	editor.switchToDocument((editor.currentDocument + len(editor.documents) - 1) % len(editor.documents))
+}
+
+// remove the document at 'index', an empty buffer is opened when it was the last one
+func (editor *Editor) removeDocument(index int) {
+	documents := make([]*Document, 0, len(editor.documents))
+	for i, doc := range editor.documents {
+		if i != index {
+			documents = append(documents, doc)
+		}
+	}
+
+	if len(documents) == 0 {
+		documents = append(documents, newDocument(""))
+	}
+
+	removed := editor.documents[index]
+	current := editor.getCurrentDocument()
+	if index != editor.currentDocument {
+		editor.storeCursorInDocument()
+	}
+
+	editor.documents = documents
+
+	// stay on the same document, or take the one that was before the closed one
+	next := editor.findDocumentByPointer(current)
+	if next == -1 {
+		next = max(min(index-1, len(documents)-1), 0)
+	}
+
+	editor.removeSwapFile(removed)
+	editor.replaceDocumentInWindows(removed, documents[next])
+	editor.showDocument(next)
+}
+
+// close the document at 'index', asking to save it first if it has been modified
+func (editor *Editor) closeDocument(index int) {
+	doc := editor.documents[index]
+	if !doc.buffer.modified {
+		editor.removeDocument(index)
+		return
+	}
+
+	question := fmt.Sprintf("save the changes of %s? (y: save, n: discard, c: cancel) ", doc.getName())
+	editor.askQuestion(question, func(answer rune) error {
+		switch answer {
+		case 'y', 'Y':
+			editor.switchToDocument(editor.findDocumentByPointer(doc))
+			err := editor.saveCurrentDocument()
+			if err != nil {
+				editor.setMessage("%s", err)
+				return nil
+			}
+			editor.removeDocument(editor.findDocumentByPointer(doc))
+		case 'n', 'N':
+			editor.removeDocument(editor.findDocumentByPointer(doc))
+		}
+
+		return nil
+	})
+}
+
+// read the file at 'path' into a new document
+func loadDocument(path string) (*Document, error) {
+	fileContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument(path)
+	err = doc.buffer.load(fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.diskState, err = getFileState(path, fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
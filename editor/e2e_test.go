@@ -0,0 +1,755 @@
+package editor_test
+
+import (
+	"edit/editor"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	E2E_SCREEN_WIDTH  = 100
+	E2E_SCREEN_HEIGHT = 32
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the end-to-end tests")
+
+// an editor drawing on a simulated screen, driven by the events of the test
+type harness struct {
+	t      *testing.T
+	editor *editor.Editor
+	screen tcell.SimulationScreen
+	dir    string // directory of the files of the test
+}
+
+// start an editor in a directory holding the files of 'files' (name to content), config.OpenedFile is relative to it
+func newHarness(t *testing.T, config editor.EditorConfiguration, files map[string]string) *harness {
+	t.Helper()
+
+	// the histories must not be read from (or written to) the user configuration
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if config.OpenedFile != "" {
+		config.OpenedFile = filepath.Join(dir, config.OpenedFile)
+	}
+
+	screen := tcell.NewSimulationScreen("")
+	e, err := editor.NewWithScreen(config, screen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	screen.SetSize(E2E_SCREEN_WIDTH, E2E_SCREEN_HEIGHT)
+
+	err = e.Load()
+	if err != nil {
+		e.Close()
+		t.Fatal(err)
+	}
+
+	t.Cleanup(e.Close)
+	e.Render()
+
+	return &harness{t: t, editor: e, screen: screen, dir: dir}
+}
+
+// send the event to the editor and render it, like the main loop
+func (h *harness) send(ev tcell.Event) {
+	h.t.Helper()
+
+	h.editor.HandleEvent(ev)
+	h.editor.Render()
+}
+
+func (h *harness) key(key tcell.Key, mod tcell.ModMask) {
+	h.t.Helper()
+	h.send(tcell.NewEventKey(key, 0, mod))
+}
+
+// type the runes of 'text', one event by rune
+func (h *harness) typeText(text string) {
+	h.t.Helper()
+
+	for _, r := range text {
+		h.send(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+}
+
+// get the rows of the screen, without the spaces at their end
+func (h *harness) rows() []string {
+	cells, width, height := h.screen.GetContents()
+
+	rows := make([]string, height)
+	for y := range rows {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			runes := cells[y*width+x].Runes
+			if len(runes) == 0 {
+				row.WriteRune(' ')
+				continue
+			}
+			row.WriteRune(runes[0])
+		}
+		rows[y] = strings.TrimRight(row.String(), " ")
+	}
+
+	return rows
+}
+
+// handle the events the editor posts to itself (the results of its background work) until a row contains 'text'
+func (h *harness) waitForRow(text string) {
+	h.t.Helper()
+
+	for {
+		for _, row := range h.rows() {
+			if strings.Contains(row, text) {
+				return
+			}
+		}
+
+		h.send(h.editor.PollEvent())
+	}
+}
+
+func (h *harness) assertCursor(x, y int) {
+	h.t.Helper()
+
+	cx, cy, visible := h.screen.GetCursor()
+	if !visible || cx != x || cy != y {
+		h.t.Errorf("cursor at (%d, %d) (visible: %t), expected at (%d, %d)", cx, cy, visible, x, y)
+	}
+}
+
+// check that the row 'y' of the screen contains 'text'
+func (h *harness) assertRowContains(y int, text string) {
+	h.t.Helper()
+
+	if row := h.rows()[y]; !strings.Contains(row, text) {
+		h.t.Errorf("row %d is %q, expected it to contain %q", y, row, text)
+	}
+}
+
+// compare the screen with the golden file testdata/e2e/'name'.golden, rewritten with -update
+func (h *harness) assertGolden(name string) {
+	h.t.Helper()
+
+	path := filepath.Join("testdata", "e2e", name+".golden")
+	got := strings.Join(h.rows(), "\n") + "\n"
+
+	if *update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(got), 0644)
+		}
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("%s (run the tests with -update to create it)", err)
+	}
+
+	if got != string(want) {
+		h.t.Errorf("the screen differs from %s:\n%s", path, got)
+	}
+}
+
+func (h *harness) readFile(name string) string {
+	h.t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(h.dir, name))
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	return string(content)
+}
+
+func TestE2EOpenFile(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "main.go"}, map[string]string{
+		"main.go": "package main\n\nfunc main() {\n    println(\"hello\")\n}\n",
+	})
+
+	h.assertCursor(0, 1)
+	h.assertGolden("open_file")
+}
+
+func TestE2ETyping(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "first\nsecond\n",
+	})
+
+	h.key(tcell.KeyDown, tcell.ModNone)
+	h.typeText("the ")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.typeText("(x")
+
+	h.assertRowContains(2, "the")
+	h.assertRowContains(3, "(x)second")
+	h.assertCursor(2, 3)
+	h.assertGolden("typing")
+}
+
+func TestE2EQuitAsksToSave(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "first\n",
+	})
+
+	h.typeText("new ")
+	h.key(tcell.KeyEscape, tcell.ModNone)
+
+	if !h.editor.ShouldNotQuit() {
+		t.Fatal("the editor quit without asking to save the modified buffer")
+	}
+	h.assertGolden("quit_asks_to_save")
+
+	h.typeText("y")
+
+	if h.editor.ShouldNotQuit() {
+		t.Error("the editor did not quit once the buffer was saved")
+	}
+
+	if got := h.readFile("notes.txt"); got != "new first\n" {
+		t.Errorf("notes.txt contains %q", got)
+	}
+}
+
+func TestE2EReadOnly(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt", ReadOnly: true}, map[string]string{
+		"notes.txt": "first\n",
+	})
+
+	h.typeText("x")
+
+	h.assertRowContains(1, "first")
+	h.assertCursor(0, 1)
+	h.assertGolden("read_only")
+}
+
+func TestE2ENavigatorTrashOpenedFile(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "notes\n",
+		"other.txt": "other\n",
+	})
+
+	h.typeText("new ")
+
+	// trash notes.txt, listed after the parent directory, then open other.txt in its place
+	h.key(tcell.KeyCtrlP, tcell.ModCtrl)
+	h.key(tcell.KeyDown, tcell.ModNone)
+	h.key(tcell.KeyDelete, tcell.ModNone)
+	h.typeText("y")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.assertRowContains(1, "other")
+
+	// the changes are kept, saving them asks for a file instead of writing the trashed one back
+	h.key(tcell.KeyTab, tcell.ModCtrl)
+	h.assertRowContains(1, "new notes")
+	h.key(tcell.KeyCtrlS, tcell.ModCtrl)
+	h.assertRowContains(29, "filepath:")
+
+	if _, err := os.Stat(filepath.Join(h.dir, "notes.txt")); err == nil {
+		t.Error("notes.txt was written again after being trashed")
+	}
+}
+
+func TestE2ESidebarFollowsNavigatorActions(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "a.txt"}, map[string]string{
+		"a.txt": "first\n",
+	})
+
+	h.key(tcell.KeyCtrlB, tcell.ModCtrl)
+	h.key(tcell.KeyCtrlE, tcell.ModCtrl)
+
+	// create new.txt from the navigator, then get back to a.txt, listed before it
+	h.key(tcell.KeyCtrlP, tcell.ModCtrl)
+	h.key(tcell.KeyCtrlN, tcell.ModCtrl)
+	h.typeText("new.txt")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.key(tcell.KeyUp, tcell.ModNone)
+	h.key(tcell.KeyEnter, tcell.ModNone)
+
+	for _, row := range h.rows()[1:] {
+		if strings.Contains(row, "new.txt") {
+			return
+		}
+	}
+
+	t.Fatalf("new.txt is not in the sidebar:\n%s", strings.Join(h.rows(), "\n"))
+}
+
+func TestE2EReadOnlySwapFile(t *testing.T) {
+	swap := "geditor swap 999999999\nlost\n"
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt", ReadOnly: true}, map[string]string{
+		"notes.txt":              "first\n",
+		".notes.txt.geditor.swp": swap,
+	})
+
+	// the text of the crashed session is neither restored nor discarded
+	h.typeText("r")
+	h.typeText("x")
+
+	h.assertRowContains(1, "first")
+	if content := h.readFile(".notes.txt.geditor.swp"); content != swap {
+		t.Errorf("the swap file holds %q", content)
+	}
+}
+
+func TestE2EHeadlessDoesNotWrite(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt", Headless: true}, map[string]string{
+		"notes.txt": "first\n",
+	})
+
+	h.typeText("x")
+	h.key(tcell.KeyCtrlS, tcell.ModCtrl)
+
+	h.assertRowContains(1, "xfirst")
+	if content := h.readFile("notes.txt"); content != "first\n" {
+		t.Errorf("notes.txt holds %q after a save in a headless replay", content)
+	}
+
+	// saving when closing the buffer, then when quitting, is refused too and keeps the changes
+	h.key(tcell.KeyCtrlW, tcell.ModCtrl)
+	h.typeText("y")
+	h.assertRowContains(1, "xfirst")
+
+	h.key(tcell.KeyEscape, tcell.ModNone)
+	h.typeText("y")
+	if !h.editor.ShouldNotQuit() {
+		t.Fatal("the editor quit without the changes being saved")
+	}
+
+	if content := h.readFile("notes.txt"); content != "first\n" {
+		t.Errorf("notes.txt holds %q after the saves asked on close and on quit", content)
+	}
+}
+
+func TestE2ESplitWindow(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "first\nsecond\n",
+	})
+
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModAlt))
+	h.key(tcell.KeyDown, tcell.ModNone)
+	h.typeText("!")
+
+	h.assertGolden("split_window")
+}
+
+func TestE2EMacro(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "one\ntwo\nthree\nfour\n",
+	})
+
+	// record "- " then a move to the start of the next line into the register q
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
+	h.typeText("q- ")
+	h.key(tcell.KeyDown, tcell.ModNone)
+	h.key(tcell.KeyLeft, tcell.ModNone)
+	h.key(tcell.KeyLeft, tcell.ModNone)
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
+
+	// played twice
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModAlt))
+	h.typeText("2q")
+
+	h.assertRowContains(1, "- one")
+	h.assertRowContains(2, "- two")
+	h.assertRowContains(3, "- three")
+	h.assertCursor(0, 4)
+
+	// the lines changed by the macro are undone together, not the typing recorded before
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	if rows := h.rows(); rows[1] != "- one" || rows[2] != "two" || rows[3] != "three" {
+		t.Errorf("rows are %q after undoing the macro", rows[1:4])
+	}
+}
+
+func TestE2EMacroFailure(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "one\n",
+	})
+
+	// record "x" then the play of the empty register z into the register a
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
+	h.typeText("ax")
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModAlt))
+	h.typeText("z")
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	h.assertRowContains(1, "one")
+
+	// the macro fails with the one it plays, the "x" it typed is removed
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModAlt))
+	h.typeText("a")
+
+	if row := h.rows()[1]; row != "one" {
+		t.Errorf("row 1 is %q after the macro failed", row)
+	}
+	h.assertRowContains(29, "the macro a failed")
+}
+
+func TestE2EMacroUndoInside(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "one\n",
+	})
+
+	// record "x", its undo, then the play of the empty register z into the register a
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
+	h.typeText("ax")
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModAlt))
+	h.typeText("z")
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
+
+	// the failed macro is undone, the typing before it is still in the history
+	h.typeText("y")
+	h.send(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModAlt))
+	h.typeText("a")
+	if row := h.rows()[1]; row != "yone" {
+		t.Errorf("row 1 is %q after the macro failed", row)
+	}
+
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	if row := h.rows()[1]; row != "one" {
+		t.Errorf("row 1 is %q after undoing the typing before the macro", row)
+	}
+}
+
+func TestE2EResize(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&content, "line %d %s end\n", i, strings.Repeat("-", 60))
+	}
+
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "long.txt"}, map[string]string{
+		"long.txt": content.String(),
+	})
+
+	// the cursor goes to the end of the line 20, visible in the 100 columns
+	for i := 0; i < 20; i++ {
+		h.key(tcell.KeyDown, tcell.ModNone)
+	}
+	for i := 0; i < 71; i++ {
+		h.key(tcell.KeyRight, tcell.ModNone)
+	}
+
+	h.screen.SetSize(40, 12)
+	h.send(tcell.NewEventResize(40, 12))
+
+	x, y, visible := h.screen.GetCursor()
+	if !visible || x < 0 || x >= 40 || y < 1 || y >= 12-3 {
+		t.Fatalf("cursor at (%d, %d) (visible: %t), outside of the text area of the 40x12 screen", x, y, visible)
+	}
+
+	h.assertRowContains(y, "- end")
+	h.assertRowContains(12-2, "20:71")
+	h.assertGolden("resize")
+}
+
+func (h *harness) click(x, y int) {
+	h.t.Helper()
+	h.send(tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone))
+	h.send(tcell.NewEventMouse(x, y, tcell.ButtonNone, tcell.ModNone))
+}
+
+func TestE2EMouse(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&content, "line %d some_word\n", i)
+	}
+
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": content.String(),
+	})
+
+	// the text starts under the tabs bar
+	h.click(3, 3)
+	h.assertCursor(3, 3)
+	h.assertRowContains(30, "2:3")
+
+	// a click after the end of a line goes to its end
+	h.click(50, 4)
+	h.assertCursor(len("line 3 some_word"), 4)
+
+	// dragging selects the text, typing replaces it
+	h.send(tcell.NewEventMouse(0, 1, tcell.Button1, tcell.ModNone))
+	h.send(tcell.NewEventMouse(5, 1, tcell.Button1, tcell.ModNone))
+	h.send(tcell.NewEventMouse(5, 1, tcell.ButtonNone, tcell.ModNone))
+	h.typeText("L")
+	h.assertRowContains(1, "L0 some_word")
+
+	// a double click selects a word, a triple click the line
+	h.click(12, 2)
+	h.click(12, 2)
+	h.typeText("w")
+	h.assertRowContains(2, "line 1 w")
+
+	h.click(2, 3)
+	h.click(2, 3)
+	h.click(2, 3)
+	h.typeText("x")
+	if row := h.rows()[3]; row != "x" {
+		t.Errorf("row 3 is %q after replacing the line selected by a triple click", row)
+	}
+
+	// the wheel scrolls the view with the cursor
+	h.send(tcell.NewEventMouse(10, 10, tcell.WheelDown, tcell.ModNone))
+	h.send(tcell.NewEventMouse(10, 10, tcell.WheelDown, tcell.ModNone))
+	h.assertRowContains(1, "line 6 some_word")
+}
+
+func TestE2EMouseNavigator(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "."}, map[string]string{
+		"a.txt": "first\n",
+		"b.txt": "second\n",
+	})
+
+	for y, row := range h.rows() {
+		if row == "b.txt" {
+			h.click(1, y)
+			h.assertRowContains(1, "second")
+			return
+		}
+	}
+
+	t.Fatalf("b.txt is not in the navigator:\n%s", strings.Join(h.rows(), "\n"))
+}
+
+func TestE2EUndo(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "one two one\n",
+	})
+
+	// the typed chars are undone together
+	h.typeText("ab ")
+	h.assertRowContains(1, "ab one two one")
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	if row := h.rows()[1]; row != "one two one" {
+		t.Fatalf("row 1 is %q after undoing the typing", row)
+	}
+
+	// replacing all the matches is one step
+	h.key(tcell.KeyCtrlR, tcell.ModCtrl)
+	h.typeText("one")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.typeText("1")
+	h.key(tcell.KeyCtrlA, tcell.ModCtrl)
+	h.typeText("y")
+	if row := h.rows()[1]; row != "1 two 1" {
+		t.Fatalf("row 1 is %q after replacing all the matches", row)
+	}
+
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	if row := h.rows()[1]; row != "one two one" {
+		t.Fatalf("row 1 is %q after undoing the replace", row)
+	}
+
+	h.key(tcell.KeyCtrlY, tcell.ModCtrl)
+	if row := h.rows()[1]; row != "1 two 1" {
+		t.Fatalf("row 1 is %q after redoing the replace", row)
+	}
+}
+
+func TestE2EReplaceOnCursorInSelection(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "a a a a a\n",
+	})
+
+	// the selection "a a a", its matches are replaced one by one with a longer text
+	for i := 0; i < 5; i++ {
+		h.key(tcell.KeyRight, tcell.ModShift)
+	}
+	h.key(tcell.KeyCtrlR, tcell.ModCtrl)
+	h.typeText("a")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.typeText("bbbb")
+	for i := 0; i < 6; i++ {
+		h.key(tcell.KeyEnter, tcell.ModNone)
+	}
+
+	if row := h.rows()[1]; row != "bbbb bbbb bbbb a a" {
+		t.Errorf("row 1 is %q after replacing the matches of the selection", row)
+	}
+}
+
+func TestE2EFinderNonASCII(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt":  "notes\n",
+		"résumé.txt": "cv\n",
+	})
+
+	h.key(tcell.KeyCtrlO, tcell.ModCtrl)
+	h.typeText("sum")
+
+	// the files come from the indexing in the background
+	h.waitForRow("résumé.txt")
+}
+
+func TestE2EFinderFromNavigator(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "."}, map[string]string{
+		"notes.txt": "notes\n",
+	})
+
+	// the same key opens the finder while editing and in the navigator
+	h.key(tcell.KeyCtrlO, tcell.ModCtrl)
+	h.typeText("nts")
+	h.waitForRow("1/1 files")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.assertRowContains(1, "notes")
+}
+
+func TestE2EScriptReplaceWithoutMatch(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "cmds.txt")
+	file := filepath.Join(dir, "notes.txt")
+
+	for path, content := range map[string]string{script: "replace missing found\nsave\n", file: "notes\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := editor.RunScript(editor.EditorConfiguration{}, script, []string{file})
+	if err == nil || !strings.Contains(err.Error(), `"missing" not found`) {
+		t.Errorf("the script ended with %v, expected the replace to fail", err)
+	}
+}
+
+func TestE2EUndoPerBuffer(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "a.txt"}, map[string]string{
+		"a.txt": "first\n",
+		"b.txt": "second\n",
+	})
+
+	h.typeText("A")
+
+	// open b.txt from the navigator, after the parent directory and a.txt
+	h.key(tcell.KeyCtrlP, tcell.ModCtrl)
+	h.key(tcell.KeyDown, tcell.ModNone)
+	h.key(tcell.KeyDown, tcell.ModNone)
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.typeText("B")
+
+	// each buffer undoes its own changes
+	h.key(tcell.KeyTab, tcell.ModCtrl)
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	if row := h.rows()[1]; row != "first" {
+		t.Errorf("row 1 of a.txt is %q after undoing its typing", row)
+	}
+
+	h.key(tcell.KeyTab, tcell.ModCtrl)
+	h.assertRowContains(1, "Bsecond")
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	if row := h.rows()[1]; row != "second" {
+		t.Errorf("row 1 of b.txt is %q after undoing its typing", row)
+	}
+
+	h.key(tcell.KeyCtrlZ, tcell.ModCtrl)
+	h.assertRowContains(29, "nothing to undo")
+}
+
+func TestE2EReplaceInLineSelection(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "aa aa aa\naa\n",
+	})
+
+	// the selection "aa aa" fills the field, and only its matches are replaced
+	for i := 0; i < 5; i++ {
+		h.key(tcell.KeyRight, tcell.ModShift)
+	}
+	h.key(tcell.KeyCtrlR, tcell.ModCtrl)
+	h.assertRowContains(29, "aa aa")
+
+	h.typeText("aa")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.typeText("b")
+	h.key(tcell.KeyCtrlA, tcell.ModCtrl)
+	h.typeText("y")
+
+	if rows := h.rows(); rows[1] != "b b aa" || rows[2] != "aa" {
+		t.Errorf("rows are %q and %q after replacing in the selection", rows[1], rows[2])
+	}
+}
+
+func TestE2ENavigatorRenameOpenedFile(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{OpenedFile: "notes.txt"}, map[string]string{
+		"notes.txt": "notes\n",
+	})
+
+	h.typeText("new ")
+	h.key(tcell.KeyCtrlP, tcell.ModCtrl)
+
+	// a name can not lead out of the listed directory
+	h.key(tcell.KeyCtrlN, tcell.ModCtrl)
+	h.typeText("../out.txt")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	if _, err := os.Stat(filepath.Join(h.dir, "..", "out.txt")); err == nil {
+		t.Fatal("out.txt was created out of the listed directory")
+	}
+
+	// the parent directory is listed first
+	h.key(tcell.KeyDown, tcell.ModNone)
+
+	h.key(tcell.KeyCtrlR, tcell.ModCtrl)
+	for range "notes.txt" {
+		h.key(tcell.KeyBackspace2, tcell.ModNone)
+	}
+	h.typeText("renamed.txt")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+
+	// the opened document follows its file, saving it does not write the old one again
+	h.key(tcell.KeyEnter, tcell.ModNone)
+	h.key(tcell.KeyCtrlS, tcell.ModCtrl)
+
+	if content := h.readFile("renamed.txt"); content != "new notes\n" {
+		t.Errorf("renamed.txt holds %q", content)
+	}
+
+	if _, err := os.Stat(filepath.Join(h.dir, "notes.txt")); err == nil {
+		t.Error("notes.txt was written again after being renamed")
+	}
+}
+
+func TestE2ESaveAsFailure(t *testing.T) {
+	h := newHarness(t, editor.EditorConfiguration{}, nil)
+
+	h.typeText("x")
+	h.key(tcell.KeyCtrlS, tcell.ModCtrl)
+	h.typeText("/nonexistent/dir/x.txt")
+	h.key(tcell.KeyEnter, tcell.ModNone)
+
+	// the failure is reported, the buffer and the prompt are still there
+	if !h.editor.ShouldNotQuit() {
+		t.Fatal("the editor quit when the save failed")
+	}
+	h.assertRowContains(29, "nonexistent")
+
+	h.typeText("2")
+	h.assertRowContains(29, "filepath: /nonexistent/dir/x.txt2")
+
+	// escape closes the prompt, it does not quit
+	h.key(tcell.KeyEscape, tcell.ModNone)
+	h.typeText("y")
+	h.assertRowContains(1, "xy")
+	if !h.editor.ShouldNotQuit() {
+		t.Fatal("escape at the prompt quit the editor")
+	}
+}
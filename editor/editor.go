@@ -1,17 +1,20 @@
 package editor
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
 
 const (
-	PROMPT_SCREEN_LINE_BEGIN = 28
-	PROMPT_SCREEN_COL_BEGIN  = 0
+	// the prompt and the status rows are counted from the bottom of the screen, they follow its height
+	PROMPT_SCREEN_ROW_FROM_BOTTOM = 3
+	PROMPT_SCREEN_COL_BEGIN       = 0
 
-	LINE_CELL_ROW = 30
-	LINE_CELL_COL = 30
+	LINE_CELL_ROW_FROM_BOTTOM = 2
+	LINE_CELL_COL             = 30
 
 	UPPER_CURSOR_BOUNDS  = 3
 	BOTTOM_CURSOR_BOUNDS = 3
@@ -28,6 +31,10 @@ const (
 	SEARCH_MODE
 	SELECTION_MODE
 	NAVIGATION_MODE
+	GREP_MODE
+	PROJECT_REPLACE_MODE
+	FINDER_MODE
+	BUFFER_LIST_MODE
 )
 
 const (
@@ -41,9 +48,25 @@ const (
 	REPLACE
 )
 
+const (
+	CONFIRM_NONE = iota
+	CONFIRM_REPLACE_ALL
+	CONFIRM_REPLACE_EACH
+)
+
 type EditorConfiguration struct {
 	OpenedFile  string // can be a dir
 	CurrentFile string // current handled file
+	SortOrder   string // order of the entries of the navigator (name, time, size or extension)
+	ShowHidden  bool   // show the hidden files in the navigator
+	Autosave    string // seconds of inactivity before the modified files are saved, empty to never save them
+	Follow      bool   // follow the opened file, loading what is appended to it
+	ReadOnly    bool   // nothing can be changed, neither the buffers nor the files
+	ReadStdin   bool   // open the content of the standard input in a buffer
+	WriteStdout bool   // write the buffer read from the standard input (or the current one) to the standard output on exit
+	Record      string // file where the events of the terminal are recorded
+	Replay      string // recording whose events are replayed once the editor is loaded
+	Headless    bool   // draw on a simulated screen instead of the terminal (to replay a recording)
 }
 
 type EditorSelectionModeParams struct {
@@ -56,42 +79,126 @@ type EditorSearchModeParams struct {
 	current     int // points to the current location on which the cursor is focused
 	whichMode   int
 	hasReplaced bool
+	confirm     int      // the confirmation the replace mode is waiting an answer for
+	replaced    int      // number of replacements done since the confirmation started
+	inSelection bool     // restrict the search (and the replace) to a selected range
+	start       Location // start of the selected range
+	end         Location // end of the selected range (not included)
 }
 
 type EditorNavigationModeParams struct {
+	dir              string // absolute path of the listed directory
 	files            []os.DirEntry
 	currentFileIndex int
+	top              int // index of the first rendered entry
+	showHidden       bool
+	sortOrder        int
+	action           int // file operation waiting for a name or a confirmation
+}
+
+type EditorGrepModeParams struct {
+	root      string // directory searched
+	query     string // text of the last started search
+	results   []GrepResult
+	current   int // index of the selected result
+	top       int // index of the first rendered result
+	searching bool
+	id        int           // identifies the last started search, results of the older ones are dropped
+	cancel    chan struct{} // closed to stop the running search
+}
+
+type EditorProjectReplaceParams struct {
+	replacement string
+	included    []bool // which search results will be replaced
+	current     int    // index of the selected search result
+	top         int    // index of the first rendered row of the preview
+	applied     bool
+	report      []string // what happened to every file once applied
+}
+
+type EditorFinderModeParams struct {
+	root         string
+	previousMode int      // mode the finder is drawn over
+	files        []string // indexed files, relative to root
+	matches      []FinderMatch
+	current      int // index of the selected match
+	top          int // index of the first rendered match
+	indexing     bool
+	id           int           // identifies the last indexing, files of the older ones are dropped
+	cancel       chan struct{} // closed to stop the indexing
+}
+
+type EditorBufferListParams struct {
+	current int // index of the selected document
 }
 
 type EditorInternalInput struct {
-	buffers [EDITOR_BUFFER_COUNT]string
-	enabled bool
-	current int
-	req     string
+	buffers   [EDITOR_BUFFER_COUNT]string
+	enabled   bool
+	current   int
+	req       string
+	prefilled bool // the current buffer was filled by the editor, typing replaces it
 }
 
 type Editor struct {
-	screen          tcell.Screen
-	buffer          Buffer
-	realCursor      Location
-	relativeCursor  Location
-	renderingCursor Location
-	config          EditorConfiguration
-	mode            int
-	searchParams    EditorSearchModeParams
-	selParams       EditorSelectionModeParams
-	navParams       EditorNavigationModeParams
-	input           EditorInternalInput
+	screen               tcell.Screen
+	buffer               *Buffer // buffer of the current document
+	realCursor           Location
+	relativeCursor       Location
+	renderingCursor      Location
+	config               EditorConfiguration
+	mode                 int
+	searchParams         EditorSearchModeParams
+	selParams            EditorSelectionModeParams
+	navParams            EditorNavigationModeParams
+	grepParams           EditorGrepModeParams
+	projectReplaceParams EditorProjectReplaceParams
+	finderParams         EditorFinderModeParams
+	input                EditorInternalInput
+	message              string // one shot message rendered in the prompt line
+	histories            [EDITOR_BUFFER_COUNT]History
+	sidebar              EditorSidebar
+	documents            []*Document
+	window               *Window // window of the cursor
+	layout               *Layout
+	currentDocument      int
+	question             *EditorQuestion
+	nextQuestions        []*EditorQuestion // questions waiting for the current one to be answered
+	bufferListParams     EditorBufferListParams
+	swap                 EditorSwap
+	autosave             EditorAutosave
+	watcher              EditorWatcher
+	output               *Document // document read from the standard input
+	recorder             EditorRecorder
+	replay               EditorReplay
+	macros               EditorMacros
+	mouse                EditorMouse
+	undoGroup            EditorUndoGroup
 }
 
 // constructor for the editor structure
 func New(editorConfig EditorConfiguration) (*Editor, error) {
+	if editorConfig.Headless {
+		return NewWithScreen(editorConfig, tcell.NewSimulationScreen(""))
+	}
+
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		return nil, err
 	}
 
-	err = screen.Init()
+	return NewWithScreen(editorConfig, screen)
+}
+
+// constructor for the editor structure drawing on 'screen' (a tcell.SimulationScreen in the tests), it initializes it
+func NewWithScreen(editorConfig EditorConfiguration, screen tcell.Screen) (*Editor, error) {
+	err := screen.Init()
+	if err != nil {
+		screen.Fini()
+		return nil, err
+	}
+
+	editor, err := newEditor(editorConfig, screen)
 	if err != nil {
 		screen.Fini()
 		return nil, err
@@ -99,10 +206,47 @@ func New(editorConfig EditorConfiguration) (*Editor, error) {
 
 	editorStyle := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite)
 	screen.SetStyle(editorStyle)
+	// the moves of the mouse are reported only while a button is held (to select the text)
+	screen.EnableMouse(tcell.MouseButtonEvents | tcell.MouseDragEvents)
+	screen.EnableFocus()
+
+	return editor, nil
+}
+
+// create the editor state, 'screen' is nil when the editor runs without a terminal (the scripts)
+func newEditor(editorConfig EditorConfiguration, screen tcell.Screen) (*Editor, error) {
+	sortOrder := SORT_BY_NAME
+	if editorConfig.SortOrder != "" {
+		order, err := parseSortOrder(editorConfig.SortOrder)
+		if err != nil {
+			return nil, err
+		}
+		sortOrder = order
+	}
+
+	var autosaveDelay time.Duration
+	if editorConfig.Autosave != "" {
+		delay, err := parseAutosaveDelay(editorConfig.Autosave)
+		if err != nil {
+			return nil, err
+		}
+		autosaveDelay = delay
+	}
+
+	// a headless replay never writes the files it shows
+	if editorConfig.Headless {
+		autosaveDelay = 0
+	}
+
+	doc := newDocument("")
+	win := newWindow(doc)
 
 	return &Editor{
 		screen:          screen,
-		buffer:          newBuffer(),
+		buffer:          doc.buffer,
+		documents:       []*Document{doc},
+		window:          win,
+		layout:          newWindowLayout(win),
 		realCursor:      Location{},
 		relativeCursor:  Location{},
 		renderingCursor: Location{},
@@ -111,12 +255,24 @@ func New(editorConfig EditorConfiguration) (*Editor, error) {
 		searchParams:    EditorSearchModeParams{},
 		selParams:       EditorSelectionModeParams{},
 		input:           EditorInternalInput{},
-		navParams:       EditorNavigationModeParams{},
+		navParams: EditorNavigationModeParams{
+			showHidden: editorConfig.ShowHidden,
+			sortOrder:  sortOrder,
+		},
+		autosave: EditorAutosave{delay: autosaveDelay},
+		macros:   EditorMacros{registers: make(map[rune][]*tcell.EventKey)},
 	}, nil
 }
 
 // close the editor (remove the editor screen)
 func (editor *Editor) Close() {
+	editor.stopReplay()
+	editor.stopRecording()
+	editor.stopGrep()
+	editor.stopFinder()
+	editor.stopAutosave()
+	editor.stopFileWatcher()
+	editor.stopSwap()
 	editor.screen.Fini()
 }
 
@@ -130,13 +286,51 @@ func (editor *Editor) Quit() {
 	editor.mode = EXIT_MODE
 }
 
-// quit the editor and save into a hardcoded filepath
-func (editor *Editor) saveAndquit() error {
+// quit the editor losing the unsaved changes, their swap files are removed too
+func (editor *Editor) quitWithoutSaving() {
+	editor.removeSwapFiles()
 	editor.Quit()
+}
 
-	if editor.config.CurrentFile != "" {
-		return editor.save()
+// quit the editor, asking first what to do with the buffers that have unsaved changes
+func (editor *Editor) quit() {
+	var modified []*Document
+	for _, doc := range editor.getModifiedDocuments() {
+		if !editor.isOutputDocument(doc) {
+			modified = append(modified, doc)
+		}
 	}
 
-	return nil
+	if len(modified) == 0 {
+		editor.quitWithoutSaving()
+		return
+	}
+
+	what := fmt.Sprintf("%d buffers", len(modified))
+	if len(modified) == 1 {
+		what = modified[0].getName()
+	}
+
+	question := fmt.Sprintf("save the changes of %s before quitting? (y: save, n: discard, c: cancel) ", what)
+	editor.askQuestion(question, func(answer rune) error {
+		switch answer {
+		case 'y', 'Y':
+			if editor.saveDocuments(modified) {
+				editor.quitWithoutSaving()
+			}
+		case 'n', 'N':
+			editor.quitWithoutSaving()
+		}
+
+		return nil
+	})
+}
+
+// set the message rendered in the prompt line until the next event
+func (editor *Editor) setMessage(format string, args ...any) {
+	editor.message = fmt.Sprintf(format, args...)
+}
+
+func (editor *Editor) clearMessage() {
+	editor.message = ""
 }
@@ -0,0 +1,235 @@
+package editor
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const TRASH_DIR_NAME = ".geditor-trash"
+
+const (
+	SORT_BY_NAME = iota
+	SORT_BY_TIME
+	SORT_BY_SIZE
+	SORT_BY_EXTENSION
+	SORT_ORDERS_COUNT
+)
+
+var sortOrderNames = [SORT_ORDERS_COUNT]string{
+	SORT_BY_NAME:      "name",
+	SORT_BY_TIME:      "time",
+	SORT_BY_SIZE:      "size",
+	SORT_BY_EXTENSION: "extension",
+}
+
+// get the sort order named 'name' (as given in the configuration)
+func parseSortOrder(name string) (int, error) {
+	for order, orderName := range sortOrderNames {
+		if orderName == name {
+			return order, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown sort order %q", name)
+}
+
+func isHiddenFile(name string) bool {
+	return strings.HasPrefix(name, ".") && name != PARENT_DIR_NAME
+}
+
+// get the info of an entry, a zero info if it can not be read (removed while listing)
+func entryInfo(entry fs.DirEntry) (size int64, modTime time.Time) {
+	info, err := entry.Info()
+	if err != nil {
+		return 0, time.Time{}
+	}
+
+	return info.Size(), info.ModTime()
+}
+
+// sort the entries: the parent entry, then the directories, then the files, each group in the order 'order'
+func sortEntries(entries []fs.DirEntry, order int) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+
+		if a.Name() == PARENT_DIR_NAME || b.Name() == PARENT_DIR_NAME {
+			return a.Name() == PARENT_DIR_NAME
+		}
+
+		if a.IsDir() != b.IsDir() {
+			return a.IsDir()
+		}
+
+		switch order {
+		case SORT_BY_TIME:
+			_, aTime := entryInfo(a)
+			_, bTime := entryInfo(b)
+			if !aTime.Equal(bTime) {
+				return aTime.After(bTime)
+			}
+		case SORT_BY_SIZE:
+			aSize, _ := entryInfo(a)
+			bSize, _ := entryInfo(b)
+			if aSize != bSize {
+				return aSize > bSize
+			}
+		case SORT_BY_EXTENSION:
+			aExt, bExt := filepath.Ext(a.Name()), filepath.Ext(b.Name())
+			if aExt != bExt {
+				return aExt < bExt
+			}
+		}
+
+		return strings.ToLower(a.Name()) < strings.ToLower(b.Name())
+	})
+}
+
+// check the name of an entry made in the listed directory, it can not lead out of it
+func checkEntryName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%q is not a valid name", name)
+	}
+
+	return nil
+}
+
+// create an empty file, or a directory when the name ends with a '/'
+func createEntry(dir, name string) (string, error) {
+	isDir := strings.HasSuffix(name, "/")
+	name = strings.TrimSuffix(name, "/")
+
+	if err := checkEntryName(name); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Lstat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", name)
+	}
+
+	if isDir {
+		return path, os.Mkdir(path, 0755)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return path, f.Close()
+}
+
+// rename the entry at 'path', the new name is relative to its directory
+func renameEntry(path, newName string) (string, error) {
+	if err := checkEntryName(newName); err != nil {
+		return "", err
+	}
+
+	newPath := filepath.Join(filepath.Dir(path), newName)
+	if _, err := os.Lstat(newPath); err == nil {
+		return "", fmt.Errorf("%s already exists", newName)
+	}
+
+	return newPath, os.Rename(path, newPath)
+}
+
+// copy a file (keeping its permissions), a symbolic link, or a whole directory
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+
+	case info.IsDir():
+		err = os.Mkdir(dst, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// get the default name of the copy of 'name' ("main.go" gives "main_copy.go")
+func getCopyName(name string) string {
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+
+	return strings.TrimSuffix(name, ext) + "_copy" + ext
+}
+
+// copy the entry at 'path' next to it under the name 'newName'
+func duplicateEntry(path, newName string) (string, error) {
+	if err := checkEntryName(newName); err != nil {
+		return "", err
+	}
+
+	newPath := filepath.Join(filepath.Dir(path), newName)
+	if _, err := os.Lstat(newPath); err == nil {
+		return "", fmt.Errorf("%s already exists", newName)
+	}
+
+	return newPath, copyPath(path, newPath)
+}
+
+// move the entry at 'path' into the trash directory of its directory instead of removing it
+func trashEntry(path string) (string, error) {
+	trashDir := filepath.Join(filepath.Dir(path), TRASH_DIR_NAME)
+	err := os.MkdirAll(trashDir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	trashPath := filepath.Join(trashDir, filepath.Base(path))
+	if _, err := os.Lstat(trashPath); err == nil {
+		trashPath += "." + time.Now().Format("20060102-150405.000000000")
+	}
+
+	return trashPath, os.Rename(path, trashPath)
+}
@@ -0,0 +1,288 @@
+package editor
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const WATCH_POLL_INTERVAL = time.Second
+
+var errFileChanged = errors.New("the file changed on disk since it was loaded, save it with Ctrl+S to overwrite it")
+
+// what the editor knows of a file on the disk, to tell when another program changed it
+type FileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// get the state of the file at 'path', 'content' is its content when it is already read
+func getFileState(path string, content []byte) (FileState, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return FileState{}, nil
+	}
+
+	if err != nil {
+		return FileState{}, err
+	}
+
+	if content == nil {
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return FileState{}, err
+		}
+	}
+
+	return FileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		hash:    sha256.Sum256(content),
+	}, nil
+}
+
+// check if the file of the document is not the one it was loaded from (or saved to),
+// the content is only read when its time or its size changed
+func (doc *Document) getDiskChange() (state FileState, changed bool, err error) {
+	if !doc.diskState.exists {
+		return doc.diskState, false, nil
+	}
+
+	info, err := os.Stat(doc.path)
+	if os.IsNotExist(err) {
+		return FileState{}, false, nil
+	}
+
+	if err != nil {
+		return doc.diskState, false, err
+	}
+
+	if info.ModTime().Equal(doc.diskState.modTime) && info.Size() == doc.diskState.size {
+		return doc.diskState, false, nil
+	}
+
+	state, err = getFileState(doc.path, nil)
+	if err != nil {
+		return doc.diskState, false, err
+	}
+
+	return state, state.hash != doc.diskState.hash, nil
+}
+
+// watches the files of the documents and calls a function when one of them may have changed
+type FileWatcher interface {
+	watch(path string)
+	close()
+}
+
+// a watcher checking all the files regularly, for the systems without notifications
+type pollingWatcher struct {
+	cancel chan struct{}
+}
+
+func newPollingWatcher(notify func()) *pollingWatcher {
+	watcher := &pollingWatcher{cancel: make(chan struct{})}
+
+	go func() {
+		ticker := time.NewTicker(WATCH_POLL_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-watcher.cancel:
+				return
+			case <-ticker.C:
+				notify()
+			}
+		}
+	}()
+
+	return watcher
+}
+
+func (watcher *pollingWatcher) watch(path string) {}
+
+func (watcher *pollingWatcher) close() {
+	close(watcher.cancel)
+}
+
+// event posted when a file of a document may have changed
+type EventFileCheck struct {
+	tcell.EventTime
+}
+
+type EditorWatcher struct {
+	watcher FileWatcher
+	pending *atomic.Bool // a check is already posted, the notifications until it is handled are merged into it
+	cancel  chan struct{}
+}
+
+// watch the files of the documents with the notifications of the system, or by polling them when there are none
+func (e *Editor) startFileWatcher() {
+	pending := &atomic.Bool{}
+	cancel := make(chan struct{})
+	screen := e.screen
+
+	notify := func() {
+		if pending.CompareAndSwap(false, true) {
+			ev := &EventFileCheck{}
+			ev.SetEventNow()
+			postEvent(screen, ev, cancel)
+		}
+	}
+
+	watcher, err := newSystemWatcher(notify)
+	if err != nil {
+		watcher = newPollingWatcher(notify)
+	}
+
+	e.watcher = EditorWatcher{watcher: watcher, pending: pending, cancel: cancel}
+
+	for _, doc := range e.documents {
+		e.watchDocument(doc)
+	}
+}
+
+func (e *Editor) stopFileWatcher() {
+	if e.watcher.watcher == nil {
+		return
+	}
+
+	close(e.watcher.cancel)
+	e.watcher.watcher.close()
+	e.watcher = EditorWatcher{}
+}
+
+func (e *Editor) watchDocument(doc *Document) {
+	if e.watcher.watcher != nil && doc.path != "" {
+		e.watcher.watcher.watch(doc.path)
+	}
+}
+
+// look for the files of the documents changed by another program and ask to reload them
+func (e *Editor) checkDocumentFiles() {
+	if e.watcher.pending != nil {
+		e.watcher.pending.Store(false)
+	}
+
+	for _, doc := range e.documents {
+		if doc.path == "" || doc.reloadAsked {
+			continue
+		}
+
+		if doc.following {
+			e.followFile(doc)
+			continue
+		}
+
+		state, changed, err := doc.getDiskChange()
+		if err != nil {
+			continue
+		}
+
+		if doc.diskState.exists && !state.exists {
+			doc.diskState = state
+			e.setMessage("%s was removed from the disk", doc.getName())
+			continue
+		}
+
+		if !changed {
+			// only the time changed (touched or saved again with the same content)
+			doc.diskState = state
+			continue
+		}
+
+		if state == doc.ignoredDiskState {
+			continue
+		}
+
+		e.askReload(doc, state)
+	}
+}
+
+// ask to replace the content of the document with the one of its file, changed by another program
+func (e *Editor) askReload(doc *Document, state FileState) {
+	question := fmt.Sprintf("%s changed on disk, reload it? (y: reload, n: keep this version) ", doc.getName())
+	if doc.buffer.modified {
+		question = fmt.Sprintf("%s changed on disk, reload it and lose the unsaved changes? (y: reload, n: keep this version) ", doc.getName())
+	}
+
+	doc.reloadAsked = true
+	e.askQuestion(question, func(answer rune) error {
+		doc.reloadAsked = false
+
+		if answer != 'y' && answer != 'Y' {
+			// not asked again for this change, saving will need to be forced
+			doc.ignoredDiskState = state
+			return nil
+		}
+
+		err := e.reloadDocument(doc)
+		if err != nil {
+			e.setMessage("%s", err)
+		}
+
+		return nil
+	})
+}
+
+// replace the content of the document with the one of its file
+func (e *Editor) reloadDocument(doc *Document) error {
+	content, err := os.ReadFile(doc.path)
+	if err != nil {
+		return err
+	}
+
+	state, err := getFileState(doc.path, content)
+	if err != nil {
+		return err
+	}
+
+	err = doc.buffer.load(content)
+	if err != nil {
+		return err
+	}
+
+	doc.diskState = state
+	doc.markUndoStepsModified()
+	e.removeSwapFile(doc)
+
+	if doc == e.getCurrentDocument() {
+		e.realCursor = e.buffer.clampLocation(e.realCursor)
+	}
+
+	return nil
+}
+
+// save the current document, asking to overwrite its file when another program changed it
+func (e *Editor) saveCurrentDocumentOrAsk() error {
+	err := e.save()
+	if !errors.Is(err, errFileChanged) {
+		return err
+	}
+
+	doc := e.getCurrentDocument()
+	question := fmt.Sprintf("%s changed on disk since it was loaded, overwrite it? (y/n) ", doc.getName())
+	e.askQuestion(question, func(answer rune) error {
+		if answer != 'y' && answer != 'Y' {
+			return nil
+		}
+
+		err := doc.overwrite()
+		if err != nil {
+			e.setMessage("%s", err)
+		}
+
+		return nil
+	})
+
+	return nil
+}
@@ -0,0 +1,117 @@
+//go:build linux
+
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// the changes of a directory that may change one of its files (editors and tools often write a new file then rename it)
+const INOTIFY_WATCH_MASK = syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+	syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM
+
+// a watcher notified by inotify, it watches the directories of the files so a replaced file is still watched
+type inotifyWatcher struct {
+	fd      int
+	file    *os.File
+	mutex   sync.Mutex
+	dirs    map[int]string  // watched directories by watch descriptor
+	wds     map[string]int  // watch descriptors by directory
+	files   map[string]bool // watched files
+	polling *pollingWatcher // checks the files regularly once a directory can not be watched (too many watches...)
+	notify  func()
+}
+
+func newSystemWatcher(notify func()) (FileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &inotifyWatcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		dirs:   map[int]string{},
+		wds:    map[string]int{},
+		files:  map[string]bool{},
+		notify: notify,
+	}
+
+	go watcher.run(notify)
+	return watcher, nil
+}
+
+// read the events until the watcher is closed, 'notify' is called for the ones of the watched files
+func (watcher *inotifyWatcher) run(notify func()) {
+	buf := make([]byte, 64*1024)
+
+	for {
+		n, err := watcher.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := min(nameStart+int(event.Len), n)
+			offset = nameEnd
+
+			// the name is padded with NUL bytes
+			name := string(buf[nameStart:nameEnd])
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+
+			if event.Mask&syscall.IN_Q_OVERFLOW != 0 || watcher.isWatched(int(event.Wd), name) {
+				notify()
+			}
+		}
+	}
+}
+
+func (watcher *inotifyWatcher) isWatched(wd int, name string) bool {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	dir, ok := watcher.dirs[wd]
+	return ok && watcher.files[filepath.Join(dir, name)]
+}
+
+func (watcher *inotifyWatcher) watch(path string) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	watcher.files[path] = true
+
+	dir := filepath.Dir(path)
+	if _, ok := watcher.wds[dir]; ok {
+		return
+	}
+
+	wd, err := syscall.InotifyAddWatch(watcher.fd, dir, INOTIFY_WATCH_MASK)
+	if err != nil {
+		if watcher.polling == nil {
+			watcher.polling = newPollingWatcher(watcher.notify)
+		}
+		return
+	}
+
+	watcher.wds[dir] = wd
+	watcher.dirs[wd] = dir
+}
+
+func (watcher *inotifyWatcher) close() {
+	watcher.file.Close()
+
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	if watcher.polling != nil {
+		watcher.polling.close()
+	}
+}
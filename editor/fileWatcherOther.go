@@ -0,0 +1,10 @@
+//go:build !linux
+
+package editor
+
+import "errors"
+
+// the other systems are polled
+func newSystemWatcher(notify func()) (FileWatcher, error) {
+	return nil, errors.New("no file notifications on this system")
+}
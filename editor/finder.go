@@ -0,0 +1,219 @@
+package editor
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	FINDER_MAX_FILES      = 100000
+	FINDER_BATCH_SIZE     = 256
+	FINDER_MATCH_SCORE    = 16
+	FINDER_CONSECUTIVE    = 24 // bonus when the char follows the previous matched one
+	FINDER_SEGMENT_START  = 32 // bonus when the char starts a word of the path
+	FINDER_BASENAME_BONUS = 8  // bonus for every char matched in the file name
+	FINDER_GAP_PENALTY    = 1  // penalty for every skipped char
+)
+
+type FinderMatch struct {
+	path      string // relative to the root of the finder
+	score     int
+	positions []int // indices of the matched bytes in the path
+}
+
+// check if the char at 'index' starts a word of the path (after a separator or a lower to upper case change)
+func isSegmentStart(text string, index int) bool {
+	if index == 0 {
+		return true
+	}
+
+	prev, c := rune(text[index-1]), rune(text[index])
+	if strings.ContainsRune("/\\_-. ", prev) {
+		return true
+	}
+
+	return unicode.IsLower(prev) && unicode.IsUpper(c)
+}
+
+// lower the ASCII letters only, so the indices of the bytes do not change
+func asciiLower(text string) string {
+	lower := []byte(text)
+	for i, c := range lower {
+		if c >= 'A' && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+
+	return string(lower)
+}
+
+// match the pattern greedily from the byte 'start' of the text
+func fuzzyMatchFrom(pattern, text string, start int) (int, []int, bool) {
+	lowerText := asciiLower(text)
+	baseStart := strings.LastIndex(text, "/") + 1
+
+	positions := make([]int, 0, len(pattern))
+	score := 0
+	prev := -1
+
+	j := start
+	for i := 0; i < len(pattern); i++ {
+		for j < len(lowerText) && lowerText[j] != pattern[i] {
+			j++
+		}
+
+		if j == len(lowerText) {
+			return 0, nil, false
+		}
+
+		score += FINDER_MATCH_SCORE
+		if prev != -1 {
+			if j == prev+1 {
+				score += FINDER_CONSECUTIVE
+			} else {
+				score -= (j - prev - 1) * FINDER_GAP_PENALTY
+			}
+		}
+
+		if isSegmentStart(text, j) {
+			score += FINDER_SEGMENT_START
+		}
+
+		if j >= baseStart {
+			score += FINDER_BASENAME_BONUS
+		}
+
+		positions = append(positions, j)
+		prev = j
+		j++
+	}
+
+	// prefer the short paths
+	score -= len(text) / 8
+	return score, positions, true
+}
+
+// check if the pattern is a subsequence of the text (ignoring the case) and score the best way to match it
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	pattern = asciiLower(pattern)
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	lowerText := asciiLower(text)
+
+	bestScore, found := 0, false
+	var bestPositions []int
+
+	for start := strings.IndexByte(lowerText, pattern[0]); start != -1; {
+		score, positions, ok := fuzzyMatchFrom(pattern, text, start)
+		if !ok {
+			break
+		}
+
+		if !found || score > bestScore {
+			bestScore, bestPositions, found = score, positions, true
+		}
+
+		next := strings.IndexByte(lowerText[start+1:], pattern[0])
+		if next == -1 {
+			break
+		}
+		start += next + 1
+	}
+
+	return bestScore, bestPositions, found
+}
+
+// rank the files matching the pattern, the best match first
+func rankFiles(pattern string, files []string) []FinderMatch {
+	var matches []FinderMatch
+
+	for _, file := range files {
+		score, positions, ok := fuzzyMatch(pattern, file)
+		if ok {
+			matches = append(matches, FinderMatch{path: file, score: score, positions: positions})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].isBetterThan(matches[j])
+	})
+
+	return matches
+}
+
+func (match FinderMatch) isBetterThan(other FinderMatch) bool {
+	if match.score != other.score {
+		return match.score > other.score
+	}
+
+	return len(match.path) < len(other.path)
+}
+
+// merge two ranked lists of matches into one, the first list wins the ties like when all the files are ranked at once
+func mergeMatches(first, second []FinderMatch) []FinderMatch {
+	merged := make([]FinderMatch, 0, len(first)+len(second))
+
+	for len(first) > 0 && len(second) > 0 {
+		if second[0].isBetterThan(first[0]) {
+			merged = append(merged, second[0])
+			second = second[1:]
+		} else {
+			merged = append(merged, first[0])
+			first = first[1:]
+		}
+	}
+
+	merged = append(merged, first...)
+	return append(merged, second...)
+}
+
+// index the files of the tree rooted at 'root' (paths relative to it), the files are given to 'emit' by batches
+func indexProjectFiles(root string, cancel <-chan struct{}, emit func([]string)) {
+	// the walk stops when the indexing is cancelled, or once enough files are indexed
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-cancel:
+		case <-done:
+		}
+		close(stop)
+	}()
+
+	paths := make(chan string)
+	go func() {
+		walkProjectFiles(root, paths, stop)
+		close(paths)
+	}()
+
+	var batch []string
+	count := 0
+
+	for path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+
+		batch = append(batch, filepath.ToSlash(rel))
+		count++
+
+		if len(batch) >= FINDER_BATCH_SIZE {
+			emit(batch)
+			batch = nil
+		}
+
+		if count >= FINDER_MAX_FILES {
+			break
+		}
+	}
+
+	if len(batch) != 0 {
+		emit(batch)
+	}
+}
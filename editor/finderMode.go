@@ -0,0 +1,183 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	FINDER_BOX_MAX_WIDTH  = 80
+	FINDER_BOX_MAX_HEIGHT = 20
+	FINDER_BOX_MARGIN     = 2
+)
+
+// event posted by the indexing goroutine with a batch of files
+type EventFinderFiles struct {
+	tcell.EventTime
+	id    int
+	files []string
+}
+
+// event posted when all the files are indexed
+type EventFinderDone struct {
+	tcell.EventTime
+	id int
+}
+
+// open the finder over the current mode, indexing the files under the navigation directory
+func (e *Editor) setFinderMode() {
+	e.stopFinder()
+
+	root := e.navParams.dir
+	if root == "" {
+		var err error
+		root, err = filepath.Abs(e.getNavigationStartDir())
+		if err != nil {
+			e.setMessage("%s", err)
+			return
+		}
+	}
+
+	e.finderParams = EditorFinderModeParams{
+		root:         root,
+		previousMode: e.mode,
+		id:           e.finderParams.id + 1,
+	}
+
+	e.mode = FINDER_MODE
+	e.enableInputBuffer()
+	e.setInputCurrentBuffer(INPUT_TEXT)
+	e.setInputBufferInputRequestString("> ")
+
+	id := e.finderParams.id
+	cancel := make(chan struct{})
+	e.finderParams.cancel = cancel
+	e.finderParams.indexing = true
+
+	screen := e.screen
+	go func() {
+		indexProjectFiles(root, cancel, func(files []string) {
+			ev := &EventFinderFiles{id: id, files: files}
+			ev.SetEventNow()
+			postEvent(screen, ev, cancel)
+		})
+
+		ev := &EventFinderDone{id: id}
+		ev.SetEventNow()
+		postEvent(screen, ev, cancel)
+	}()
+}
+
+// stop indexing the files (if it is still running)
+func (e *Editor) stopFinder() {
+	if e.finderParams.cancel != nil {
+		close(e.finderParams.cancel)
+		e.finderParams.cancel = nil
+	}
+
+	e.finderParams.indexing = false
+}
+
+// rank the indexed files again with the typed pattern
+func (e *Editor) updateFinderMatches() {
+	e.finderParams.matches = rankFiles(e.input.buffers[INPUT_TEXT], e.finderParams.files)
+
+	if e.finderParams.current >= len(e.finderParams.matches) {
+		e.finderParams.current = 0
+	}
+}
+
+func (e *Editor) appendFinderFiles(ev *EventFinderFiles) {
+	if ev.id != e.finderParams.id {
+		return
+	}
+
+	// only the new files are ranked, the files indexed before keep their rank for the same pattern
+	e.finderParams.files = append(e.finderParams.files, ev.files...)
+	e.finderParams.matches = mergeMatches(e.finderParams.matches, rankFiles(e.input.buffers[INPUT_TEXT], ev.files))
+}
+
+func (e *Editor) finishFinderIndexing(ev *EventFinderDone) {
+	if ev.id != e.finderParams.id {
+		return
+	}
+
+	e.stopFinder()
+}
+
+// close the finder and get back to the mode it was opened from
+func (e *Editor) switchFromFinderMode() {
+	e.stopFinder()
+	e.resetInput()
+	e.mode = e.finderParams.previousMode
+}
+
+func (e *Editor) openFinderMatch() error {
+	if len(e.finderParams.matches) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(e.finderParams.root, filepath.FromSlash(e.finderParams.matches[e.finderParams.current].path))
+
+	e.stopFinder()
+	e.resetInput()
+
+	err := e.openFile(path)
+	if err != nil {
+		e.mode = e.finderParams.previousMode
+		e.setMessage("%s", err)
+	}
+
+	return nil
+}
+
+func (e *Editor) updateFinderIndexCursorDown() {
+	if e.finderParams.current < len(e.finderParams.matches)-1 {
+		e.finderParams.current++
+	}
+}
+
+func (e *Editor) updateFinderIndexCursorUp() {
+	if e.finderParams.current > 0 {
+		e.finderParams.current--
+	}
+}
+
+// handle the keys and the indexed files of the finder
+func (e *Editor) handleFinderModeEvent(ev tcell.Event) error {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyEscape:
+			e.switchFromFinderMode()
+		case tcell.KeyEnter:
+			return e.openFinderMatch()
+		case tcell.KeyDown:
+			e.updateFinderIndexCursorDown()
+		case tcell.KeyUp:
+			e.updateFinderIndexCursorUp()
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			e.removeCharFromInputBuffer()
+			e.finderParams.current = 0
+			e.updateFinderMatches()
+		case tcell.KeyRune:
+			e.insertCharToInputBuffer(ev.Rune())
+			e.finderParams.current = 0
+			e.updateFinderMatches()
+		}
+	}
+
+	return nil
+}
+
+// get the status of the finder shown at the bottom of its box
+func (e *Editor) finderStatus() string {
+	status := fmt.Sprintf("%d/%d files", len(e.finderParams.matches), len(e.finderParams.files))
+	if e.finderParams.indexing {
+		status += " (indexing...)"
+	}
+
+	return status
+}
@@ -0,0 +1,155 @@
+package editor
+
+import (
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// follow the file of the current document: what is appended to it is loaded as it comes and shown (like `tail -f`)
+func (e *Editor) startFollowing() {
+	doc := e.getCurrentDocument()
+	if doc.path == "" {
+		e.setMessage("the buffer has no file to follow")
+		return
+	}
+
+	if doc.buffer.modified {
+		e.setMessage("%s has unsaved changes, save them before following it", doc.getName())
+		return
+	}
+
+	content, err := os.ReadFile(doc.path)
+	if err != nil {
+		e.setMessage("%s", err)
+		return
+	}
+
+	content = completeRunes(content)
+	doc.buffer.load(content)
+	doc.following = true
+	doc.followOffset = int64(len(content))
+	e.removeSwapFile(doc)
+
+	e.realCursor = newLocation(e.buffer.count()-1, 0)
+}
+
+// stop loading what is appended to the file of the current document
+func (e *Editor) stopFollowing() {
+	doc := e.getCurrentDocument()
+	doc.following = false
+
+	// the file is the one loaded until the next change
+	state, err := getFileState(doc.path, nil)
+	if err == nil {
+		doc.diskState = state
+	}
+}
+
+func (e *Editor) toggleFollowing() {
+	if e.getCurrentDocument().following {
+		e.stopFollowing()
+		return
+	}
+
+	e.startFollowing()
+}
+
+// cut the end of 'content' when it is the beginning of a char whose other bytes are not written yet
+func completeRunes(content []byte) []byte {
+	for i := len(content) - 1; i >= 0 && i >= len(content)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(content[i]) {
+			if !utf8.FullRune(content[i:]) {
+				return content[:i]
+			}
+			break
+		}
+	}
+
+	return content
+}
+
+// load what was appended to the followed file, the windows whose cursor is on the last line keep showing the end
+func (e *Editor) followFile(doc *Document) {
+	f, err := os.Open(doc.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+
+	lastLine := doc.buffer.count() - 1
+	isCurrent := doc == e.getCurrentDocument()
+
+	// the file was truncated (rotated log), it is loaded again
+	if info.Size() < doc.followOffset {
+		content, err := io.ReadAll(f)
+		if err != nil {
+			return
+		}
+
+		content = completeRunes(content)
+		doc.buffer.load(content)
+		doc.followOffset = int64(len(content))
+	} else {
+		_, err = f.Seek(doc.followOffset, io.SeekStart)
+		if err != nil {
+			return
+		}
+
+		appended, err := io.ReadAll(f)
+		if err != nil {
+			return
+		}
+
+		appended = completeRunes(appended)
+		if len(appended) == 0 {
+			return
+		}
+
+		doc.buffer.appendContent(appended)
+		doc.followOffset += int64(len(appended))
+	}
+
+	end := newLocation(doc.buffer.count()-1, 0)
+	for _, win := range e.layout.windows() {
+		if win.document == doc && win != e.window && win.realCursor.getLine() >= lastLine {
+			win.realCursor = end
+		}
+	}
+
+	if !isCurrent {
+		return
+	}
+
+	if e.realCursor.getLine() >= lastLine {
+		e.realCursor = end
+	} else {
+		e.realCursor = e.buffer.clampLocation(e.realCursor)
+	}
+
+	// the highlighted matches include the new lines
+	if e.mode == SEARCH_MODE {
+		e.updateSearchLocations(e.input.buffers[INPUT_TEXT])
+	}
+}
+
+// handle the key following the file of the current document (Alt + F)
+func (e *Editor) handleFollowKeyEvent(ev *tcell.EventKey) bool {
+	if ev.Modifiers()&tcell.ModAlt == 0 || ev.Key() != tcell.KeyRune {
+		return false
+	}
+
+	if ev.Rune() != 'f' && ev.Rune() != 'F' {
+		return false
+	}
+
+	e.toggleFollowing()
+	return true
+}
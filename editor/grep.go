@@ -0,0 +1,274 @@
+package editor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	GREP_WORKERS_COUNT  = 8
+	GREP_MAX_RESULTS    = 10000
+	GREP_BINARY_PROBE   = 8000 // number of bytes read to decide if a file is binary
+	GREP_SNIPPET_LENGTH = 120
+)
+
+type GrepResult struct {
+	path    string
+	line    int
+	col     int    // column in the editor buffer (tabs are expanded)
+	rawCol  int    // column in the line of the file
+	content string // line of the file as it was when searched
+	snippet string
+}
+
+// a single rule of a .gitignore file
+type ignoreRule struct {
+	base     string // directory of the .gitignore file
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool // the pattern contains a '/' so it is matched against the path relative to base
+}
+
+type ignoreRules []ignoreRule
+
+// parse the content of the .gitignore found in the directory 'base'
+func parseIgnoreRules(base string, content []byte) ignoreRules {
+	var rules ignoreRules
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		line = strings.TrimPrefix(line, "**/")
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func (rule *ignoreRule) match(path string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	rel, err := filepath.Rel(rule.base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	if rule.anchored {
+		matched, _ := filepath.Match(rule.pattern, filepath.ToSlash(rel))
+		return matched
+	}
+
+	matched, _ := filepath.Match(rule.pattern, filepath.Base(path))
+	return matched
+}
+
+// check if the path is ignored, the last matching rule wins
+func (rules ignoreRules) ignored(path string, isDir bool) bool {
+	ignored := false
+
+	for i := range rules {
+		if rules[i].match(path, isDir) {
+			ignored = !rules[i].negate
+		}
+	}
+
+	return ignored
+}
+
+// check if the beginning of the content looks like a binary file
+func isBinary(content []byte) bool {
+	if len(content) > GREP_BINARY_PROBE {
+		content = content[:GREP_BINARY_PROBE]
+	}
+
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// walk the tree rooted at 'root' and send the path of every regular file that is not ignored
+func walkProjectFiles(root string, paths chan<- string, cancel <-chan struct{}) {
+	var rules ignoreRules
+
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		select {
+		case <-cancel:
+			return filepath.SkipAll
+		default:
+		}
+
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if path != root && rules.ignored(path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" || entry.Name() == TRASH_DIR_NAME {
+				return filepath.SkipDir
+			}
+
+			content, err := os.ReadFile(filepath.Join(path, ".gitignore"))
+			if err == nil {
+				rules = append(rules, parseIgnoreRules(path, content)...)
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || isSwapFile(entry.Name()) {
+			return nil
+		}
+
+		select {
+		case paths <- path:
+		case <-cancel:
+			return filepath.SkipAll
+		}
+
+		return nil
+	})
+}
+
+// search 'text' in the file at 'path', binary files have no matches
+func grepFile(path string, text string) ([]GrepResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	probe, _ := reader.Peek(GREP_BINARY_PROBE)
+	if isBinary(probe) {
+		return nil, nil
+	}
+
+	var results []GrepResult
+
+	for row := 0; ; row++ {
+		content, err := reader.ReadString('\n')
+		if content != "" {
+			results = append(results, grepLine(path, row, strings.TrimRight(content, "\r\n"), text)...)
+		}
+
+		if err == io.EOF {
+			return results, nil
+		}
+
+		if err != nil {
+			return results, err
+		}
+	}
+}
+
+// search the non overlapping occurrences of 'text' in a line of a file
+func grepLine(path string, row int, content string, text string) []GrepResult {
+	var results []GrepResult
+
+	line := newLine(content)
+	end := 0
+	for _, col := range line.search(0, text) {
+		if col < end {
+			continue
+		}
+		end = col + len(text)
+
+		results = append(results, GrepResult{
+			path:    path,
+			line:    row,
+			col:     expandedCol(content, col),
+			rawCol:  col,
+			content: content,
+			snippet: grepSnippet(expandTabs(content)),
+		})
+	}
+
+	return results
+}
+
+// the editor expands the tabs into spaces, so do the same to get what the buffer will contain
+func expandTabs(content string) string {
+	return strings.ReplaceAll(content, "\t", strings.Repeat(" ", BUFFER_TAB_SIZE))
+}
+
+// get the column in the editor buffer of the byte at 'col' in the line of the file
+func expandedCol(content string, col int) int {
+	return col + strings.Count(content[:col], "\t")*(BUFFER_TAB_SIZE-1)
+}
+
+func grepSnippet(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > GREP_SNIPPET_LENGTH {
+		return line[:GREP_SNIPPET_LENGTH]
+	}
+
+	return line
+}
+
+// search 'text' in all the files of the tree rooted at 'root', the results of every file are given to 'emit'
+// from several goroutines, it returns when the search is done or cancelled
+func grepProject(root string, text string, cancel <-chan struct{}, emit func([]GrepResult)) {
+	paths := make(chan string)
+	go func() {
+		walkProjectFiles(root, paths, cancel)
+		close(paths)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < GREP_WORKERS_COUNT; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for path := range paths {
+				// unreadable files are skipped like the binary ones
+				results, _ := grepFile(path, text)
+				if len(results) != 0 {
+					emit(results)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
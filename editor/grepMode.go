@@ -0,0 +1,244 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// event posted by the search goroutines when a file has matches
+type EventGrepResults struct {
+	tcell.EventTime
+	id      int
+	results []GrepResult
+}
+
+// event posted when the search is over
+type EventGrepDone struct {
+	tcell.EventTime
+	id int
+}
+
+func (e *Editor) setGrepMode() {
+	e.stopGrep()
+
+	// the results of a previous search still in the event queue are dropped
+	e.grepParams = EditorGrepModeParams{
+		root: e.navParams.dir,
+		id:   e.grepParams.id + 1,
+	}
+
+	e.mode = GREP_MODE
+	e.enableInputBuffer()
+	e.setInputCurrentBuffer(INPUT_TEXT)
+	e.setInputBufferInputRequestString("search in files: ")
+}
+
+// cancel the running search (if any), the results still in the event queue will be dropped
+func (e *Editor) stopGrep() {
+	if e.grepParams.cancel != nil {
+		close(e.grepParams.cancel)
+		e.grepParams.cancel = nil
+	}
+
+	e.grepParams.searching = false
+}
+
+// start searching the text of the input buffer in all the files under the root directory
+func (e *Editor) startGrep() {
+	e.stopGrep()
+
+	text := e.input.buffers[INPUT_TEXT]
+	e.grepParams.id++
+	e.grepParams.query = text
+	e.grepParams.results = nil
+	e.grepParams.current = 0
+	e.grepParams.top = 0
+
+	if text == "" {
+		return
+	}
+
+	id := e.grepParams.id
+	cancel := make(chan struct{})
+	e.grepParams.cancel = cancel
+	e.grepParams.searching = true
+
+	screen := e.screen
+	root := e.grepParams.root
+	go func() {
+		grepProject(root, text, cancel, func(results []GrepResult) {
+			ev := &EventGrepResults{id: id, results: results}
+			ev.SetEventNow()
+			postEvent(screen, ev, cancel)
+		})
+
+		ev := &EventGrepDone{id: id}
+		ev.SetEventNow()
+		postEvent(screen, ev, cancel)
+	}()
+}
+
+func (e *Editor) appendGrepResults(ev *EventGrepResults) {
+	if ev.id != e.grepParams.id {
+		return
+	}
+
+	room := GREP_MAX_RESULTS - len(e.grepParams.results)
+	if len(ev.results) >= room {
+		e.grepParams.results = append(e.grepParams.results, ev.results[:room]...)
+		e.stopGrep()
+		return
+	}
+
+	e.grepParams.results = append(e.grepParams.results, ev.results...)
+}
+
+func (e *Editor) finishGrep(ev *EventGrepDone) {
+	if ev.id != e.grepParams.id {
+		return
+	}
+
+	e.stopGrep()
+}
+
+func (e *Editor) updateGrepIndexCursorDown() {
+	if e.grepParams.current < len(e.grepParams.results)-1 {
+		e.grepParams.current++
+	}
+}
+
+func (e *Editor) updateGrepIndexCursorUp() {
+	if e.grepParams.current > 0 {
+		e.grepParams.current--
+	}
+}
+
+// open the file of the selected result and set the cursor on the match
+func (e *Editor) openGrepResult() error {
+	if len(e.grepParams.results) == 0 {
+		return nil
+	}
+
+	result := e.grepParams.results[e.grepParams.current]
+
+	e.stopGrep()
+	e.resetInput()
+
+	err := e.openFile(result.path)
+	if err != nil {
+		return err
+	}
+
+	if e.buffer.isValidLine(result.line) && result.col <= e.buffer.lines[result.line].count() {
+		e.realCursor = newLocation(result.line, result.col)
+	}
+
+	return nil
+}
+
+func (e *Editor) switchToNavigationFromGrepMode() {
+	e.stopGrep()
+	e.resetInput()
+	e.setNavigationMode()
+}
+
+// ask for the text replacing the matches in all the files
+func (e *Editor) askProjectReplacement() {
+	if !e.canChangeFiles() {
+		return
+	}
+
+	if e.grepParams.searching {
+		e.setMessage("wait for the search to finish before replacing")
+		return
+	}
+
+	if len(e.grepParams.results) == 0 {
+		e.setMessage("no match to replace")
+		return
+	}
+
+	e.setInputCurrentBuffer(NEW_TEXT)
+	e.setInputBufferInputRequestString("replace in files with: ")
+}
+
+func (e *Editor) handleEscapeKeyInGrepMode() {
+	if e.getInputCurrentBuffer() == NEW_TEXT {
+		e.setInputCurrentBuffer(INPUT_TEXT)
+		e.setInputBufferInputRequestString("search in files: ")
+		return
+	}
+
+	e.switchToNavigationFromGrepMode()
+}
+
+func (e *Editor) handleEnterKeyInGrepMode() error {
+	if e.getInputCurrentBuffer() == NEW_TEXT {
+		e.setProjectReplaceMode()
+		return nil
+	}
+
+	// a new query has been typed, search it first
+	if e.input.buffers[INPUT_TEXT] != e.grepParams.query {
+		e.startGrep()
+		return nil
+	}
+
+	return e.openGrepResult()
+}
+
+// handle the keys and the results of the search in files
+func (e *Editor) handleGrepModeEvent(ev tcell.Event) error {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyEscape:
+			e.handleEscapeKeyInGrepMode()
+		case tcell.KeyEnter:
+			return e.handleEnterKeyInGrepMode()
+		case tcell.KeyCtrlR:
+			e.askProjectReplacement()
+		case tcell.KeyDown:
+			e.updateGrepIndexCursorDown()
+		case tcell.KeyUp:
+			e.updateGrepIndexCursorUp()
+		case tcell.KeyBackspace2:
+			e.removeCharFromInputBuffer()
+		case tcell.KeyRune:
+			e.insertCharToInputBuffer(ev.Rune())
+		}
+	}
+
+	return nil
+}
+
+// get the path relative to the searched directory
+func (e *Editor) relativeToGrepRoot(path string) string {
+	rel, err := filepath.Rel(e.grepParams.root, path)
+	if err != nil {
+		return path
+	}
+
+	return rel
+}
+
+// get the text shown for a result
+func (e *Editor) grepResultText(result GrepResult) string {
+	return fmt.Sprintf("%s:%d:%s", e.relativeToGrepRoot(result.path), result.line+1, result.snippet)
+}
+
+// get the status of the search shown under the results
+func (e *Editor) grepStatus() string {
+	status := fmt.Sprintf("%d results", len(e.grepParams.results))
+	if e.grepParams.searching {
+		status += " (searching...)"
+	}
+
+	if len(e.grepParams.results) >= GREP_MAX_RESULTS {
+		status += " (too many results, refine the search)"
+	}
+
+	return status
+}
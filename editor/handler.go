@@ -1,14 +1,73 @@
 package editor
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const POST_EVENT_RETRY_DELAY = 10 * time.Millisecond
 
 // get an event from the event loop
 func (editor *Editor) PollEvent() tcell.Event {
 	return editor.screen.PollEvent()
 }
 
-// handle the event
-func (editor *Editor) HandleEvent(ev tcell.Event) error {
+// handle the event, an action that fails is reported in the message and the editing goes on (nothing unsaved is lost)
+func (editor *Editor) HandleEvent(ev tcell.Event) {
+	if err := editor.recordEvent(ev); err != nil {
+		editor.stopRecording()
+		// shown once the event is handled, the handling clears the previous message
+		defer editor.setMessage("the recording stopped: %s", err)
+	}
+
+	if _, ok := ev.(*EventReplayEnd); ok {
+		editor.finishReplay()
+		return
+	}
+
+	err := editor.handleEvent(ev)
+	if err != nil {
+		editor.setMessage("%s", err)
+	}
+}
+
+// handle an event without recording it, for the events the editor sends to itself (the keys of the macros...)
+func (editor *Editor) handleEvent(ev tcell.Event) error {
+	// the ticks come in the middle of the typing, they must not change what is shown
+	if _, ok := ev.(*EventSwapTick); ok {
+		editor.updateSwapFiles()
+		return nil
+	}
+
+	if editor.handleAutosaveEvent(ev) {
+		return nil
+	}
+
+	if _, ok := ev.(*EventFileCheck); ok {
+		editor.checkDocumentFiles()
+		return nil
+	}
+
+	if _, ok := ev.(*tcell.EventResize); ok {
+		editor.handleResize()
+		return nil
+	}
+
+	if editor.handleSearchResultEvent(ev) {
+		return nil
+	}
+
+	editor.clearMessage()
+
+	if editor.handleMacroEvent(ev) {
+		return nil
+	}
+
+	if editor.hasQuestion() {
+		return editor.handleQuestionEvent(ev)
+	}
+
 	switch editor.mode {
 	case INSERT_MODE:
 		return editor.handleInsertModeEvent(ev)
@@ -18,7 +77,45 @@ func (editor *Editor) HandleEvent(ev tcell.Event) error {
 		return editor.handleSelectionModeEvent(ev)
 	case NAVIGATION_MODE:
 		return editor.handleNavigationModeEvent(ev)
+	case GREP_MODE:
+		return editor.handleGrepModeEvent(ev)
+	case PROJECT_REPLACE_MODE:
+		return editor.handleProjectReplaceModeEvent(ev)
+	case FINDER_MODE:
+		return editor.handleFinderModeEvent(ev)
+	case BUFFER_LIST_MODE:
+		return editor.handleBufferListModeEvent(ev)
 	}
 
 	return nil
 }
+
+// handle the results of the searches running in the background, they come in the middle of the typing so they must
+// not clear the message; the results of a search that was stopped (or replaced by another one) are dropped
+func (editor *Editor) handleSearchResultEvent(ev tcell.Event) bool {
+	switch ev := ev.(type) {
+	case *EventGrepResults:
+		editor.appendGrepResults(ev)
+	case *EventGrepDone:
+		editor.finishGrep(ev)
+	case *EventFinderFiles:
+		editor.appendFinderFiles(ev)
+	case *EventFinderDone:
+		editor.finishFinderIndexing(ev)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// post an event from another goroutine, waiting while the event queue is full unless 'cancel' gets closed
+func postEvent(screen tcell.Screen, ev tcell.Event, cancel <-chan struct{}) {
+	for screen.PostEvent(ev) != nil {
+		select {
+		case <-cancel:
+			return
+		case <-time.After(POST_EVENT_RETRY_DELAY):
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	HISTORY_MAX_ENTRIES = 100
+	CONFIG_DIR_NAME     = "geditor"
+)
+
+// history files names (indexed by the input buffer they belong to)
+var historyFileNames = [EDITOR_BUFFER_COUNT]string{
+	INPUT_TEXT: "search_history",
+	NEW_TEXT:   "replace_history",
+}
+
+type History struct {
+	entries []string
+	index   int    // entry being shown in the prompt, len(entries) means the typed text
+	draft   string // text typed before walking through the history
+}
+
+// get the directory where the editor keeps its own files
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, CONFIG_DIR_NAME), nil
+}
+
+// add an entry to the history (moved to the end if it already exists)
+func (history *History) add(entry string) {
+	if entry == "" {
+		return
+	}
+
+	entries := make([]string, 0, len(history.entries)+1)
+	for _, e := range history.entries {
+		if e != entry {
+			entries = append(entries, e)
+		}
+	}
+	entries = append(entries, entry)
+
+	if len(entries) > HISTORY_MAX_ENTRIES {
+		entries = entries[len(entries)-HISTORY_MAX_ENTRIES:]
+	}
+
+	history.entries = entries
+	history.reset()
+}
+
+// get back to the typed text
+func (history *History) reset() {
+	history.index = len(history.entries)
+	history.draft = ""
+}
+
+// get the previous (older) entry, 'current' is the text in the prompt
+func (history *History) prev(current string) (string, bool) {
+	if history.index == 0 || len(history.entries) == 0 {
+		return "", false
+	}
+
+	if history.index >= len(history.entries) {
+		history.index = len(history.entries)
+		history.draft = current
+	}
+
+	history.index--
+	return history.entries[history.index], true
+}
+
+// get the next (newer) entry, the typed text comes after the last entry
+func (history *History) next() (string, bool) {
+	if history.index >= len(history.entries) {
+		return "", false
+	}
+
+	history.index++
+	if history.index == len(history.entries) {
+		return history.draft, true
+	}
+
+	return history.entries[history.index], true
+}
+
+func (history *History) load(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	history.entries = nil
+	for _, entry := range strings.Split(string(content), "\n") {
+		if entry != "" {
+			history.entries = append(history.entries, entry)
+		}
+	}
+
+	history.reset()
+	return nil
+}
+
+func (history *History) save(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(history.entries, "\n")+"\n"), 0644)
+}
+
+// load the search and the replace histories, missing files are not an error
+func (editor *Editor) loadHistories() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+
+	for i := range editor.histories {
+		err = editor.histories[i].load(filepath.Join(dir, historyFileNames[i]))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add the content of the input buffer 'which' to its history and write the history to the disk
+func (editor *Editor) addToHistory(which int) {
+	editor.histories[which].add(editor.input.buffers[which])
+
+	dir, err := configDir()
+	if err != nil {
+		return
+	}
+
+	// the history is a convenience, failing to persist it should not stop the editing
+	editor.histories[which].save(filepath.Join(dir, historyFileNames[which]))
+}
+
+// show the previous entry of the history of the current input buffer
+func (editor *Editor) showPrevHistoryEntry() bool {
+	which := editor.getInputCurrentBuffer()
+
+	entry, ok := editor.histories[which].prev(editor.input.buffers[which])
+	if !ok {
+		return false
+	}
+
+	editor.input.buffers[which] = entry
+	editor.input.prefilled = false
+	return true
+}
+
+// show the next entry of the history of the current input buffer
+func (editor *Editor) showNextHistoryEntry() bool {
+	which := editor.getInputCurrentBuffer()
+
+	entry, ok := editor.histories[which].next()
+	if !ok {
+		return false
+	}
+
+	editor.input.buffers[which] = entry
+	editor.input.prefilled = false
+	return true
+}
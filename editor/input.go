@@ -22,6 +22,11 @@ func (editor *Editor) insertCharToInputBuffer(c rune) {
 		return
 	}
 
+	if editor.input.prefilled {
+		editor.input.buffers[editor.input.current] = ""
+		editor.input.prefilled = false
+	}
+
 	editor.input.buffers[editor.input.current] += string(c)
 }
 
@@ -30,6 +35,8 @@ func (editor *Editor) removeCharFromInputBuffer() {
 		return
 	}
 
+	editor.input.prefilled = false
+
 	if len(editor.input.buffers[editor.input.current]) == 0 {
 		return
 	}
@@ -41,6 +48,13 @@ func (editor *Editor) removeCharFromInputBuffer() {
 
 func (editor *Editor) setInputCurrentBuffer(current int) {
 	editor.input.current = current
+	editor.input.prefilled = false
+}
+
+// fill the current buffer with a text the user can keep or type over
+func (editor *Editor) prefillInputBuffer(text string) {
+	editor.input.buffers[editor.input.current] = text
+	editor.input.prefilled = text != ""
 }
 
 func (editor *Editor) getInputCurrentBuffer() int {
@@ -9,22 +9,50 @@ import (
 
 // insert char in the editor buffer
 func (editor *Editor) insertChar(c rune) error {
+	if !editor.canEdit() {
+		return nil
+	}
+
+	editor.saveUndoStep(UNDO_TYPING)
+	defer editor.markTypingEnd()
+
 	return editor.buffer.insertChar(c, &editor.realCursor)
 }
 
 // remove a char from the editor buffer
 func (editor *Editor) removeChar() error {
+	if !editor.canEdit() {
+		return nil
+	}
+
+	editor.saveUndoStep(UNDO_TYPING)
+	defer editor.markTypingEnd()
+
 	return editor.buffer.removeChar(&editor.realCursor)
 }
 
 // insert the new line char '\n' into the editor buffer
 func (editor *Editor) insertNewLine() error {
-	return editor.buffer.insertNewLine(&editor.realCursor)
+	if !editor.canEdit() {
+		return nil
+	}
+
+	editor.saveUndoStep(UNDO_TYPING)
+	defer editor.markTypingEnd()
+
+	return editor.insertText("\n")
 }
 
 // insert the tab char '\t' into the editor buffer
 func (editor *Editor) insertTab() error {
-	return editor.buffer.insertTab(&editor.realCursor)
+	if !editor.canEdit() {
+		return nil
+	}
+
+	editor.saveUndoStep(UNDO_TYPING)
+	defer editor.markTypingEnd()
+
+	return editor.insertText("\t")
 }
 
 // move the (main) editor cursor up
@@ -232,8 +260,12 @@ func writeFile(text string) {
 }
 
 func (editor *Editor) handleFileSavingInInsertMode() error {
+	if !editor.canEdit() || !editor.canChangeFiles() {
+		return nil
+	}
+
 	if editor.config.CurrentFile != "" {
-		return editor.save()
+		return editor.saveCurrentDocumentOrAsk()
 	}
 
 	editor.enableInputBuffer()
@@ -254,13 +286,31 @@ func (editor *Editor) handleCtrlCommandsInInsertMode(evKey tcell.EventKey) error
 	case tcell.KeyCtrlS:
 		return editor.handleFileSavingInInsertMode()
 	case tcell.KeyCtrlF:
-		editor.setSearchMode()
-		editor.setSearchSubMode(SEARCH)
+		editor.setSearchModeWithText(SEARCH, editor.buffer.wordAt(editor.realCursor))
 	case tcell.KeyCtrlR:
-		editor.setSearchMode()
-		editor.setSearchSubMode(REPLACE)
+		editor.setSearchModeWithText(REPLACE, editor.buffer.wordAt(editor.realCursor))
 	case tcell.KeyCtrlP:
-		editor.setNavigationModeFromInsertMode()
+		editor.setNavigationMode()
+	case tcell.KeyCtrlO:
+		editor.setFinderMode()
+	case tcell.KeyTab, tcell.KeyPgDn:
+		editor.switchToNextDocument()
+	case tcell.KeyPgUp:
+		editor.switchToPrevDocument()
+	case tcell.KeyCtrlW:
+		editor.closeDocument(editor.currentDocument)
+	case tcell.KeyCtrlL:
+		editor.setBufferListMode()
+	case tcell.KeyCtrlQ:
+		editor.quitWithoutSaving()
+	case tcell.KeyCtrlZ:
+		editor.undo()
+	case tcell.KeyCtrlY:
+		editor.redo()
+	case tcell.KeyCtrlB:
+		editor.toggleSidebar()
+	case tcell.KeyCtrlE:
+		editor.switchSidebarFocus()
 	default:
 		break
 	}
@@ -275,10 +325,17 @@ func (editor *Editor) handleEnterKeyInInsertMode() error {
 			return nil
 		}
 
-		editor.config.CurrentFile = editor.input.buffers[editor.getInputCurrentBuffer()]
+		doc := editor.getCurrentDocument()
+		path, diskState := doc.path, doc.diskState
+
+		editor.setCurrentFile(editor.input.buffers[editor.getInputCurrentBuffer()])
 		err := editor.save()
 		if err != nil {
-			return err
+			// the document keeps its previous file, the prompt stays open to correct the path
+			doc.path, doc.diskState = path, diskState
+			editor.config.CurrentFile = path
+			editor.setMessage("%s", err)
+			return nil
 		}
 
 		editor.resetInput()
@@ -288,6 +345,16 @@ func (editor *Editor) handleEnterKeyInInsertMode() error {
 	return editor.insertNewLine()
 }
 
+// close the prompt of the file path, or quit when there is none
+func (editor *Editor) handleEscapeKeyInInsertMode() {
+	if editor.inputBufferIsEnabled() {
+		editor.resetInput()
+		return
+	}
+
+	editor.quit()
+}
+
 func (editor *Editor) handleBackSpaceKeyInInsertMode() error {
 	if editor.inputBufferIsEnabled() {
 		editor.removeCharFromInputBuffer()
@@ -307,11 +374,32 @@ func (editor *Editor) handleRuneKeyInInsertMode(c rune) error {
 
 // handle the normal mode commands
 func (editor *Editor) handleInsertModeEvent(ev tcell.Event) error {
+	// the tabs and the windows can be used from the text and from the sidebar
+	switch ev := ev.(type) {
+	case *tcell.EventMouse:
+		if editor.handleTabsMouseEvent(ev) || editor.handleTextMouseEvent(ev) {
+			editor.sidebar.focused = false
+			return nil
+		}
+	case *tcell.EventKey:
+		if editor.handleTabsKeyEvent(ev) || editor.handleWindowKeyEvent(ev) || editor.handleFollowKeyEvent(ev) {
+			return nil
+		}
+	}
+
+	if editor.sidebar.focused {
+		return editor.handleSidebarEvent(ev)
+	}
+
 	switch ev := ev.(type) {
 	case *tcell.EventKey:
+		if editor.handlePagerKeyEvent(ev) {
+			return nil
+		}
+
 		if ev.Modifiers()&tcell.ModShift != 0 {
 			editor.setSelectionMode()
-			return editor.HandleEvent(ev)
+			return editor.handleSelectionModeEvent(ev)
 		}
 
 		// handle the ctrl + `evKey.Key()` commands
@@ -321,7 +409,7 @@ func (editor *Editor) handleInsertModeEvent(ev tcell.Event) error {
 
 		switch {
 		case ev.Key() == tcell.KeyEscape:
-			return editor.saveAndquit()
+			editor.handleEscapeKeyInInsertMode()
 		case ev.Key() == tcell.KeyBackspace2:
 			return editor.handleBackSpaceKeyInInsertMode()
 		case ev.Key() == tcell.KeyTab:
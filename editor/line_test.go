@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineInsertString(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		col     int
+		s       string
+		want    string
+		wantCol int
+		wantErr bool
+	}{
+		{name: "empty line", content: "", col: 0, s: "ab", want: "ab", wantCol: 2},
+		{name: "start", content: "cd", col: 0, s: "ab", want: "abcd", wantCol: 2},
+		{name: "middle", content: "ad", col: 1, s: "bc", want: "abcd", wantCol: 3},
+		{name: "end", content: "ab", col: 2, s: "cd", want: "abcd", wantCol: 4},
+		{name: "empty string", content: "ab", col: 1, s: "", want: "ab", wantCol: 1},
+		{name: "after the end", content: "ab", col: 3, s: "c", want: "ab", wantCol: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := newLine(tt.content)
+			cursor := newLocation(0, tt.col)
+
+			err := line.insertString(tt.s, &cursor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error: %v, expected one: %t", err, tt.wantErr)
+			}
+
+			if line.content != tt.want || cursor.getCol() != tt.wantCol {
+				t.Errorf("got %q with the cursor at %d, expected %q at %d", line.content, cursor.getCol(), tt.want, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestLineRemoveString(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		col     int
+		count   int
+		want    string
+		wantCol int
+		wantErr bool
+	}{
+		{name: "nothing", content: "abc", col: 2, count: 0, want: "abc", wantCol: 2},
+		{name: "one char", content: "abc", col: 2, count: 1, want: "ac", wantCol: 1},
+		{name: "up to the start", content: "abc", col: 2, count: 2, want: "c", wantCol: 0},
+		{name: "at the end", content: "abc", col: 3, count: 2, want: "a", wantCol: 1},
+		{name: "before the start", content: "abc", col: 1, count: 2, want: "abc", wantCol: 1, wantErr: true},
+		{name: "after the end", content: "abc", col: 4, count: 1, want: "abc", wantCol: 4, wantErr: true},
+		{name: "empty line", content: "", col: 0, count: 1, want: "", wantCol: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := newLine(tt.content)
+			cursor := newLocation(0, tt.col)
+
+			err := line.removeString(tt.count, &cursor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error: %v, expected one: %t", err, tt.wantErr)
+			}
+
+			if line.content != tt.want || cursor.getCol() != tt.wantCol {
+				t.Errorf("got %q with the cursor at %d, expected %q at %d", line.content, cursor.getCol(), tt.want, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestLineSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		start   int
+		text    string
+		want    []int
+	}{
+		{name: "no match", content: "abc", text: "d", want: nil},
+		{name: "several matches", content: "abcabc", text: "bc", want: []int{1, 4}},
+		{name: "overlapping matches", content: "aaaa", text: "aa", want: []int{0, 1, 2}},
+		{name: "whole line", content: "abc", text: "abc", want: []int{0}},
+		{name: "longer than the line", content: "ab", text: "abc", want: nil},
+		{name: "empty line", content: "", text: "a", want: nil},
+		{name: "from an index", content: "abcabc", start: 2, text: "a", want: []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := newLine(tt.content)
+
+			got := line.search(tt.start, tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineReplace(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		col      int
+		prevText string
+		newText  string
+		want     string
+		wantCol  int
+	}{
+		{name: "same length", content: "abcd", col: 1, prevText: "bc", newText: "xy", want: "axyd", wantCol: 3},
+		{name: "longer", content: "abcd", col: 1, prevText: "bc", newText: "wxyz", want: "awxyzd", wantCol: 5},
+		{name: "shorter", content: "abcd", col: 1, prevText: "bc", newText: "x", want: "axd", wantCol: 2},
+		{name: "removed", content: "abcd", col: 1, prevText: "bc", newText: "", want: "ad", wantCol: 1},
+		{name: "start of the line", content: "abcd", col: 0, prevText: "ab", newText: "x", want: "xcd", wantCol: 1},
+		{name: "end of the line", content: "abcd", col: 2, prevText: "cd", newText: "x", want: "abx", wantCol: 3},
+		{name: "whole line", content: "abcd", col: 0, prevText: "abcd", newText: "", want: "", wantCol: 0},
+		{name: "after the end", content: "abcd", col: 5, prevText: "", newText: "x", want: "abcd", wantCol: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := newLine(tt.content)
+			loc := newLocation(0, tt.col)
+
+			line.replace(&loc, tt.prevText, tt.newText)
+			if line.content != tt.want || loc.getCol() != tt.wantCol {
+				t.Errorf("got %q with the location at %d, expected %q at %d", line.content, loc.getCol(), tt.want, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestLineSplit(t *testing.T) {
+	tests := []struct {
+		content   string
+		index     int
+		wantFirst string
+		wantLast  string
+	}{
+		{content: "abcd", index: 0, wantFirst: "", wantLast: "abcd"},
+		{content: "abcd", index: 2, wantFirst: "ab", wantLast: "cd"},
+		{content: "abcd", index: 4, wantFirst: "abcd", wantLast: ""},
+		{content: "", index: 0, wantFirst: "", wantLast: ""},
+	}
+
+	for _, tt := range tests {
+		line := newLine(tt.content)
+
+		first, last := line.Split(tt.index)
+		if first.content != tt.wantFirst || last.content != tt.wantLast {
+			t.Errorf("%q split at %d: got %q and %q, expected %q and %q", tt.content, tt.index, first.content, last.content, tt.wantFirst, tt.wantLast)
+		}
+	}
+}
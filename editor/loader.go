@@ -2,6 +2,7 @@ package editor
 
 import (
 	"os"
+	"path/filepath"
 )
 
 // load a file using the EditorConfiguration fields (passed as args)
@@ -15,47 +16,84 @@ func (editor *Editor) loadFileFromConfiguration() error {
 	}
 
 	if fileInfo.IsDir() {
+		editor.navParams.dir = editor.config.OpenedFile
 		editor.setNavigationMode()
 		return nil
 	}
 
-	editor.mode = INSERT_MODE
-
-	editor.config.CurrentFile = editor.config.OpenedFile
-	fileContent, err := os.ReadFile(editor.config.CurrentFile)
+	err = editor.openFile(editor.config.OpenedFile)
 	if err != nil {
 		return err
 	}
 
-	for _, c := range fileContent {
-		switch c {
-		case '\n':
-			err = editor.insertNewLine()
-		case '\t':
-			err = editor.insertTab()
-		default:
-			err = editor.loadCharFromFile(rune(c))
-		}
-
-		if err != nil {
-			return err
-		}
+	if editor.config.Follow {
+		editor.startFollowing()
 	}
 
 	return nil
 }
 
-// load a char from a file into the editor buffer
-func (editor *Editor) loadCharFromFile(c rune) error {
-	return editor.buffer.insertCharNormally(c, &editor.realCursor)
+// open the file at 'path' (or switch to it if it is already opened) and switch to the insert mode
+func (editor *Editor) openFile(path string) error {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	if index := editor.findDocument(path); index != -1 {
+		editor.switchToDocument(index)
+		editor.mode = INSERT_MODE
+		return nil
+	}
+
+	doc, err := loadDocument(path)
+	if err != nil {
+		return err
+	}
+
+	editor.addDocument(doc)
+	editor.mode = INSERT_MODE
+	editor.watchDocument(doc)
+	editor.checkSwapFile(doc)
+	return nil
 }
 
 // load file to the editor buffer
 // main function
 func (editor *Editor) Load() error {
-	if editor.config.OpenedFile == "" {
-		return nil
+	// the histories are a convenience, a broken history file should not prevent the editing
+	editor.loadHistories()
+	editor.loadMacros()
+	// the files of the user are left as they are during a headless replay
+	if !editor.config.Headless {
+		editor.startSwap()
+		editor.startFileWatcher()
 	}
 
-	return editor.loadFileFromConfiguration()
+	if editor.config.ReadStdin {
+		err := editor.loadStdin()
+		if err != nil {
+			return err
+		}
+	}
+
+	if editor.config.OpenedFile != "" {
+		err := editor.loadFileFromConfiguration()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the replay sets the size of the screen before the recording writes it
+	if editor.config.Replay != "" {
+		err := editor.startReplay(editor.config.Replay)
+		if err != nil {
+			return err
+		}
+	}
+
+	if editor.config.Record != "" {
+		return editor.startRecording(editor.config.Record)
+	}
+
+	return nil
 }
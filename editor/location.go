@@ -40,3 +40,12 @@ func (cursor *Location) get() (line int, col int) {
 func (cursor *Location) cmp(cur Location) bool {
 	return cursor.getLine() == cur.getLine() && cursor.getCol() == cur.getCol()
 }
+
+// check if the location comes before 'cur' in the buffer
+func (cursor *Location) isBefore(cur Location) bool {
+	if cursor.getLine() != cur.getLine() {
+		return cursor.getLine() < cur.getLine()
+	}
+
+	return cursor.getCol() < cur.getCol()
+}
@@ -0,0 +1,403 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	MACROS_FILE_NAME = "macros"
+	MACRO_MAX_DEPTH  = 8 // a macro can play another one, not endlessly
+)
+
+type EditorMacros struct {
+	registers map[rune][]*tcell.EventKey
+	recording rune              // register being recorded, 0 when none
+	keys      []*tcell.EventKey // keys recorded so far
+	playing   int               // number of macros being played (a macro can play another one)
+}
+
+// the keys by their names, to read the macros
+var keysByName = func() map[string]tcell.Key {
+	keys := make(map[string]tcell.Key, len(tcell.KeyNames))
+	for key, name := range tcell.KeyNames {
+		keys[name] = key
+	}
+	return keys
+}()
+
+var macroModifierPrefixes = []struct {
+	prefix string
+	mod    tcell.ModMask
+}{
+	{"Ctrl-", tcell.ModCtrl},
+	{"Alt-", tcell.ModAlt},
+	{"Shift-", tcell.ModShift},
+}
+
+// get the written form of the keys of a macro: the typed chars as they are, the other keys between '<' and '>'
+// ("log(<Right><Enter>", "<Ctrl-Left>", "<Alt-f>"), "<lt>" is the char '<'
+func formatMacroKeys(keys []*tcell.EventKey) string {
+	var text strings.Builder
+
+	for _, key := range keys {
+		if key.Key() == tcell.KeyRune && key.Modifiers() == tcell.ModNone && key.Rune() != '<' {
+			text.WriteRune(key.Rune())
+			continue
+		}
+
+		name, ok := tcell.KeyNames[key.Key()]
+		switch {
+		case key.Key() != tcell.KeyRune && ok:
+		case key.Rune() == '<':
+			name = "lt"
+		case key.Rune() == '>':
+			name = "gt"
+		default:
+			name = string(key.Rune())
+		}
+
+		text.WriteString("<")
+		for _, prefix := range macroModifierPrefixes {
+			// the control keys have it in their name
+			if prefix.mod == tcell.ModCtrl && strings.HasPrefix(name, prefix.prefix) {
+				continue
+			}
+			if key.Modifiers()&prefix.mod != 0 {
+				text.WriteString(prefix.prefix)
+			}
+		}
+		text.WriteString(name)
+		text.WriteString(">")
+	}
+
+	return text.String()
+}
+
+// get the key written 'name' between '<' and '>' in a macro
+func parseMacroKeyName(name string) (*tcell.EventKey, error) {
+	var mod tcell.ModMask
+
+	rest := name
+	for {
+		switch rest {
+		case "lt":
+			return tcell.NewEventKey(tcell.KeyRune, '<', mod), nil
+		case "gt":
+			return tcell.NewEventKey(tcell.KeyRune, '>', mod), nil
+		}
+
+		if key, ok := keysByName[rest]; ok {
+			// the control keys are reported with the control modifier
+			if strings.HasPrefix(rest, "Ctrl-") {
+				return tcell.NewEventKey(key, rune(key), mod|tcell.ModCtrl), nil
+			}
+			return tcell.NewEventKey(key, 0, mod), nil
+		}
+
+		if utf8.RuneCountInString(rest) == 1 {
+			r, _ := utf8.DecodeRuneInString(rest)
+			return tcell.NewEventKey(tcell.KeyRune, r, mod), nil
+		}
+
+		found := false
+		for _, prefix := range macroModifierPrefixes {
+			if strings.HasPrefix(rest, prefix.prefix) {
+				rest = strings.TrimPrefix(rest, prefix.prefix)
+				mod |= prefix.mod
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown key <%s>", name)
+		}
+	}
+}
+
+// get the keys of a macro from its written form
+func parseMacroKeys(text string) ([]*tcell.EventKey, error) {
+	var keys []*tcell.EventKey
+
+	for text != "" {
+		if text[0] != '<' {
+			r, size := utf8.DecodeRuneInString(text)
+			keys = append(keys, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			text = text[size:]
+			continue
+		}
+
+		end := strings.IndexByte(text, '>')
+		if end == -1 {
+			return nil, fmt.Errorf("missing '>' after %s", text)
+		}
+
+		key, err := parseMacroKeyName(text[1:end])
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+		text = text[end+1:]
+	}
+
+	return keys, nil
+}
+
+func isMacroRegister(r rune) bool {
+	return unicode.IsLetter(r)
+}
+
+// read the macros of the file at 'path': one by line, its register, a space then its keys ("w log(<Right><Enter>")
+func readMacros(path string) (map[rune][]*tcell.EventKey, error) {
+	registers := make(map[rune][]*tcell.EventKey)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return registers, err
+	}
+
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		register, size := utf8.DecodeRuneInString(line)
+		if !isMacroRegister(register) || !strings.HasPrefix(line[size:], " ") {
+			return registers, fmt.Errorf("%s:%d: expected a register (a letter) and a space before the keys", path, i+1)
+		}
+
+		keys, err := parseMacroKeys(line[size+1:])
+		if err != nil {
+			return registers, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+
+		registers[register] = keys
+	}
+
+	return registers, nil
+}
+
+// write the macro of the register into the file at 'path', the other lines of the file are kept
+func writeMacro(path string, register rune, keys []*tcell.EventKey) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		if line != "" && !strings.HasPrefix(line, string(register)+" ") {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, string(register)+" "+formatMacroKeys(keys))
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func macrosPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, MACROS_FILE_NAME), nil
+}
+
+// load the macros kept in the configuration, a missing file is not an error
+func (e *Editor) loadMacros() {
+	path, err := macrosPath()
+	if err != nil {
+		return
+	}
+
+	registers, err := readMacros(path)
+	if err != nil && !os.IsNotExist(err) {
+		e.setMessage("%s", err)
+	}
+
+	e.macros.registers = registers
+}
+
+func (e *Editor) startMacroRecording(register rune) {
+	e.macros.recording = register
+	e.macros.keys = nil
+	e.setMessage("recording the macro %c, Alt+M to stop", register)
+}
+
+// keep the recorded keys in the register and in the configuration
+func (e *Editor) stopMacroRecording() {
+	register, keys := e.macros.recording, e.macros.keys
+	e.macros.recording = 0
+	e.macros.keys = nil
+
+	if len(keys) == 0 {
+		e.setMessage("the macro %c is empty, it is not kept", register)
+		return
+	}
+
+	e.macros.registers[register] = keys
+	e.setMessage("macro %c: %s", register, formatMacroKeys(keys))
+
+	// the macro is still usable until the editor quits when it can not be written
+	path, err := macrosPath()
+	if err == nil {
+		err = writeMacro(path, register, keys)
+	}
+
+	if err != nil {
+		e.setMessage("macro %c not saved: %s", register, err)
+	}
+}
+
+// ask the register of the macro to record
+func (e *Editor) askMacroRecording() {
+	e.askQuestion("record a macro into the register (a letter): ", func(answer rune) error {
+		if isMacroRegister(answer) {
+			e.startMacroRecording(answer)
+		}
+		return nil
+	})
+}
+
+// ask the register of the macro to play, the digits typed before it are the number of times it is played;
+// it is played on each line of 'lines' (first and last lines) when they are given
+func (e *Editor) askMacroPlaying(count int, lines []int) {
+	question := "play the macro of the register (a letter, a number before it to repeat it): "
+	if count > 0 {
+		question = fmt.Sprintf("play the macro %d times, of the register: ", count)
+	}
+
+	e.askQuestion(question, func(answer rune) error {
+		switch {
+		case answer >= '0' && answer <= '9':
+			e.askMacroPlaying(count*10+int(answer-'0'), lines)
+		case isMacroRegister(answer):
+			err := e.playMacro(answer, max(count, 1), lines)
+			// the keys of a macro playing this one stop at the error
+			if err != nil && e.macros.playing == 0 {
+				e.setMessage("%s", err)
+				return nil
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// play the keys of the macro 'count' times, on each line of 'lines' when they are given (from the last one so the
+// lines the macro adds or removes do not move the ones left); its changes are undone together, and all of them
+// (in every document) when it fails
+func (e *Editor) playMacro(register rune, count int, lines []int) error {
+	keys, ok := e.macros.registers[register]
+	if !ok {
+		return fmt.Errorf("the register %c has no macro", register)
+	}
+
+	if e.macros.playing >= MACRO_MAX_DEPTH {
+		return fmt.Errorf("the macros play each other too deep")
+	}
+
+	doc := e.getCurrentDocument()
+
+	e.beginUndoGroup()
+	e.macros.playing++
+	err := func() error {
+		if lines == nil {
+			return e.playMacroKeys(keys, count)
+		}
+
+		for line := lines[1]; line >= lines[0]; line-- {
+			if line >= e.buffer.count() {
+				continue
+			}
+
+			e.realCursor = newLocation(line, 0)
+			err := e.playMacroKeys(keys, count)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}()
+	e.macros.playing--
+
+	// a macro played by another one fails with it, the first one undoes the changes of both
+	if err == nil || e.macros.playing > 0 {
+		e.endUndoGroup()
+		return err
+	}
+
+	e.cancelUndoGroup()
+	e.switchToDocument(e.findDocumentByPointer(doc))
+	return fmt.Errorf("the macro %c failed, nothing was changed: %w", register, err)
+}
+
+func (e *Editor) playMacroKeys(keys []*tcell.EventKey, count int) error {
+	for i := 0; i < count; i++ {
+		for _, key := range keys {
+			err := e.handleEvent(key)
+			if err != nil {
+				return err
+			}
+
+			if e.mode == EXIT_MODE {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// record the typed keys into the macro being recorded, and handle the keys of the macros:
+// Alt + M starts (or stops) a recording, Alt + P plays a macro (on each selected line in the selection mode)
+func (e *Editor) handleMacroEvent(ev tcell.Event) bool {
+	key, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+
+	isAlt := key.Modifiers()&tcell.ModAlt != 0 && key.Key() == tcell.KeyRune
+	if isAlt && unicode.ToLower(key.Rune()) == 'm' {
+		if e.macros.recording != 0 {
+			e.stopMacroRecording()
+		} else if e.macros.playing == 0 {
+			e.askMacroRecording()
+		}
+		return true
+	}
+
+	// the keys played by a macro are already in the macro being recorded, as the key playing it
+	if e.macros.recording != 0 && e.macros.playing == 0 {
+		e.macros.keys = append(e.macros.keys, key)
+	}
+
+	if !isAlt || unicode.ToLower(key.Rune()) != 'p' {
+		return false
+	}
+
+	if e.mode != SELECTION_MODE {
+		e.askMacroPlaying(0, nil)
+		return true
+	}
+
+	start, end := sortLocations(e.selParams.startLocation, e.selParams.endLocation)
+	e.switchToInsertFromSelectionMode()
+	e.askMacroPlaying(0, []int{start.getLine(), end.getLine()})
+	return true
+}
@@ -0,0 +1,222 @@
+package editor
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	MOUSE_MULTI_CLICK_DELAY = 400 * time.Millisecond // the clicks closer than this on the same cell select a word then a line
+	MOUSE_WHEEL_LINES       = 3
+)
+
+type EditorMouse struct {
+	held      bool     // the left button is held, the terminal reports the moves of the mouse until it is released
+	selecting bool     // the left button is held since a click in the text, moving the mouse selects the text
+	anchor    Location // where the left button was pressed
+	clicks    int      // 1 for a click, 2 for a double click and 3 for a triple click
+	lastClick time.Time
+	lastX     int
+	lastY     int
+}
+
+// get the window shown at the cell (x, y) of the screen, nil if there is none
+func (e *Editor) getWindowAt(x, y int) *Window {
+	for _, win := range e.layout.windows() {
+		if x >= win.x && x < win.x+win.w && y >= win.y && y < win.y+win.h {
+			return win
+		}
+	}
+
+	return nil
+}
+
+// get the location of the buffer shown at the cell (x, y) of the screen in the current window,
+// the cells after the end of a line (or of the buffer) give its end
+func (e *Editor) getLocationAt(x, y int) Location {
+	line := e.renderingCursor.getLine() + y - e.window.y
+	col := e.renderingCursor.getCol() + x - e.window.x
+
+	return e.buffer.clampLocation(newLocation(line, col))
+}
+
+// count the clicks of the left button: a click on the same cell right after the previous one makes
+// a double click, then a triple click
+func (e *Editor) countClicks(ev *tcell.EventMouse) int {
+	x, y := ev.Position()
+
+	if x == e.mouse.lastX && y == e.mouse.lastY && ev.When().Sub(e.mouse.lastClick) < MOUSE_MULTI_CLICK_DELAY {
+		e.mouse.clicks = e.mouse.clicks%3 + 1
+	} else {
+		e.mouse.clicks = 1
+	}
+
+	e.mouse.lastClick = ev.When()
+	e.mouse.lastX, e.mouse.lastY = x, y
+
+	return e.mouse.clicks
+}
+
+// scroll the view of the current window 'offset' lines down (up when negative), the cursor moves only to stay in it
+func (e *Editor) scrollView(offset int) {
+	top := min(max(e.renderingCursor.getLine()+offset, 0), e.buffer.count()-1)
+	e.renderingCursor.setLine(top)
+
+	upper, bottom := e.getCursorRowBounds()
+	if top == 0 {
+		upper = 0
+	}
+
+	line := min(max(e.realCursor.getLine(), top+upper), top+bottom)
+	e.realCursor = e.buffer.clampLocation(newLocation(line, e.realCursor.getCol()))
+}
+
+// select the text between 'start' and 'end' (not included), the cursor goes to its end
+func (e *Editor) selectText(start, end Location) {
+	e.mode = SELECTION_MODE
+	e.selParams.startLocation = start
+	e.selParams.endLocation = end
+	e.realCursor = end
+}
+
+func (e *Editor) selectWordAt(loc Location) {
+	start, end := e.buffer.wordBoundsAt(loc)
+	if start == end {
+		e.realCursor = loc
+		return
+	}
+
+	e.selectText(newLocation(loc.getLine(), start), newLocation(loc.getLine(), end))
+}
+
+func (e *Editor) selectLineAt(loc Location) {
+	line := loc.getLine()
+	e.selectText(newLocation(line, 0), newLocation(line, e.buffer.lines[line].count()))
+}
+
+// handle a press of the left button in a window: a click places the cursor, a double click selects a word
+// and a triple click selects the line
+func (e *Editor) handleTextClick(ev *tcell.EventMouse, win *Window) {
+	if e.mode == SELECTION_MODE {
+		e.switchToInsertFromSelectionMode()
+	}
+
+	e.focusWindow(win)
+
+	x, y := ev.Position()
+	loc := e.getLocationAt(x, y)
+
+	switch e.countClicks(ev) {
+	case 1:
+		e.realCursor = loc
+		e.mouse.selecting = true
+		e.mouse.anchor = loc
+	case 2:
+		e.selectWordAt(loc)
+	case 3:
+		e.selectLineAt(loc)
+	}
+}
+
+// handle a move of the mouse with the left button held: the text from where it was pressed is selected
+func (e *Editor) handleTextDrag(ev *tcell.EventMouse) {
+	x, y := ev.Position()
+	loc := e.getLocationAt(x, y)
+
+	if e.mode != SELECTION_MODE {
+		if loc == e.mouse.anchor {
+			return
+		}
+
+		e.selectText(e.mouse.anchor, loc)
+		return
+	}
+
+	e.selParams.endLocation = loc
+	e.realCursor = loc
+}
+
+// handle the mouse over the windows: clicks, drags and the wheel, false when the event is not for the text
+func (e *Editor) handleTextMouseEvent(ev *tcell.EventMouse) bool {
+	x, y := ev.Position()
+	buttons := ev.Buttons()
+
+	switch {
+	case buttons&(tcell.WheelUp|tcell.WheelDown) != 0:
+		win := e.getWindowAt(x, y)
+		if win == nil {
+			return false
+		}
+
+		// the selection follows the cursor, scrolling would move one without the other
+		if e.mode == SELECTION_MODE {
+			return true
+		}
+
+		e.focusWindow(win)
+		if buttons&tcell.WheelUp != 0 {
+			e.scrollView(-MOUSE_WHEEL_LINES)
+		} else {
+			e.scrollView(MOUSE_WHEEL_LINES)
+		}
+		return true
+
+	case buttons&tcell.Button1 == 0:
+		// the release of the button ends the selection by the mouse
+		e.mouse.held = false
+		if e.mouse.selecting {
+			e.mouse.selecting = false
+			return true
+		}
+		return false
+
+	case e.mouse.held:
+		if e.mouse.selecting {
+			e.handleTextDrag(ev)
+		}
+		return true
+	}
+
+	win := e.getWindowAt(x, y)
+	if win == nil {
+		return false
+	}
+
+	e.mouse.held = true
+	e.handleTextClick(ev, win)
+	return true
+}
+
+// handle the mouse over the entries of the navigator: a click opens an entry, the wheel moves the selection
+func (e *Editor) handleNavigationMouseEvent(ev *tcell.EventMouse) error {
+	_, y := ev.Position()
+	buttons := ev.Buttons()
+	count := len(e.navParams.files)
+
+	// an entry is opened once by a click, not again by the moves while the button is held
+	held := e.mouse.held
+	e.mouse.held = buttons&tcell.Button1 != 0
+
+	if count == 0 || held {
+		return nil
+	}
+
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		e.navParams.currentFileIndex = max(e.navParams.currentFileIndex-MOUSE_WHEEL_LINES, 0)
+	case buttons&tcell.WheelDown != 0:
+		e.navParams.currentFileIndex = min(e.navParams.currentFileIndex+MOUSE_WHEEL_LINES, count-1)
+	case buttons&tcell.Button1 != 0:
+		// the entries are rendered under the breadcrumb
+		row := y - 1
+		if row < 0 || row >= e.getNavigationListHeight() || e.navParams.top+row >= count {
+			return nil
+		}
+
+		e.navParams.currentFileIndex = e.navParams.top + row
+		return e.handleEnterKeyInNavigationMode()
+	}
+
+	return nil
+}
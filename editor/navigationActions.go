@@ -0,0 +1,234 @@
+package editor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	NAV_ACTION_NONE = iota
+	NAV_ACTION_CREATE
+	NAV_ACTION_RENAME
+	NAV_ACTION_DUPLICATE
+	NAV_ACTION_DELETE
+)
+
+// get the selected entry, the parent entry can not be renamed, duplicated or deleted
+func (e *Editor) getSelectedEntry() (string, bool) {
+	if len(e.navParams.files) == 0 {
+		return "", false
+	}
+
+	name := e.navParams.files[e.navParams.currentFileIndex].Name()
+	return name, name != PARENT_DIR_NAME
+}
+
+// ask the name (or the confirmation) needed by the action
+func (e *Editor) askNavigationAction(action int) {
+	if !e.canChangeFiles() {
+		return
+	}
+
+	name, ok := e.getSelectedEntry()
+	if action != NAV_ACTION_CREATE && !ok {
+		return
+	}
+
+	e.enableInputBuffer()
+	e.setInputCurrentBuffer(INPUT_TEXT)
+
+	switch action {
+	case NAV_ACTION_CREATE:
+		e.setInputBufferInputRequestString("new file (end with / for a directory): ")
+	case NAV_ACTION_RENAME:
+		e.setInputBufferInputRequestString("rename to: ")
+		e.input.buffers[INPUT_TEXT] = name
+	case NAV_ACTION_DUPLICATE:
+		e.setInputBufferInputRequestString("duplicate as: ")
+		e.input.buffers[INPUT_TEXT] = getCopyName(name)
+	case NAV_ACTION_DELETE:
+		e.setInputBufferInputRequestString("move " + name + " to the trash? (y/n) ")
+	}
+
+	e.navParams.action = action
+}
+
+func (e *Editor) cancelNavigationAction() {
+	e.navParams.action = NAV_ACTION_NONE
+	e.resetInput()
+}
+
+// run the action with the typed name and list the directory again
+func (e *Editor) runNavigationAction() {
+	action := e.navParams.action
+	typed := e.input.buffers[INPUT_TEXT]
+	e.cancelNavigationAction()
+
+	if typed == "" && action != NAV_ACTION_DELETE {
+		return
+	}
+
+	name, _ := e.getSelectedEntry()
+	path := filepath.Join(e.navParams.dir, name)
+
+	var newPath string
+	var err error
+
+	switch action {
+	case NAV_ACTION_CREATE:
+		newPath, err = createEntry(e.navParams.dir, typed)
+	case NAV_ACTION_RENAME:
+		newPath, err = renameEntry(path, typed)
+	case NAV_ACTION_DUPLICATE:
+		newPath, err = duplicateEntry(path, typed)
+	case NAV_ACTION_DELETE:
+		_, err = trashEntry(path)
+	}
+
+	if err != nil {
+		e.refreshDir(name)
+		e.setMessage("%s", err)
+		return
+	}
+
+	e.refreshSidebarDir(e.navParams.dir)
+
+	if action == NAV_ACTION_DELETE {
+		e.detachDocumentsFrom(path)
+
+		index := e.navParams.currentFileIndex
+		e.refreshDir("")
+		e.navParams.currentFileIndex = min(index, len(e.navParams.files)-1)
+		e.setMessage("%s moved to %s", name, TRASH_DIR_NAME)
+		return
+	}
+
+	if action == NAV_ACTION_RENAME {
+		e.renameDocumentPaths(path, newPath)
+	}
+
+	e.refreshDir(filepath.Base(newPath))
+}
+
+// make the documents opened from 'oldPath' (the file or a file of the directory) follow it to 'newPath',
+// so they are not saved again under the old name
+func (e *Editor) renameDocumentPaths(oldPath, newPath string) {
+	newPath, err := filepath.Abs(newPath)
+	if err != nil {
+		return
+	}
+
+	docs, rels := e.getDocumentsUnder(oldPath)
+	for i, doc := range docs {
+		e.removeSwapFile(doc)
+		doc.path = filepath.Join(newPath, rels[i])
+		e.watchDocument(doc)
+	}
+
+	e.config.CurrentFile = e.getCurrentDocument().path
+}
+
+// the documents opened from the trashed 'path' have no file anymore: the unchanged ones are closed, the changed
+// ones keep their content without a file, so a save asks where to write it instead of bringing the file back
+func (e *Editor) detachDocumentsFrom(path string) {
+	docs, _ := e.getDocumentsUnder(path)
+	for _, doc := range docs {
+		if !doc.buffer.modified {
+			e.removeDocument(e.findDocumentByPointer(doc))
+			continue
+		}
+
+		e.removeSwapFile(doc)
+		doc.path = ""
+		doc.diskState = FileState{}
+	}
+
+	e.config.CurrentFile = e.getCurrentDocument().path
+}
+
+// get the documents opened from 'root' (the file or a file of the directory), with their paths relative to it
+func (e *Editor) getDocumentsUnder(root string) ([]*Document, []string) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, nil
+	}
+
+	var docs []*Document
+	var rels []string
+
+	for _, doc := range e.documents {
+		if doc.path == "" {
+			continue
+		}
+
+		path, err := filepath.Abs(doc.path)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		docs = append(docs, doc)
+		rels = append(rels, rel)
+	}
+
+	return docs, rels
+}
+
+func (e *Editor) toggleHiddenFiles() {
+	e.navParams.showHidden = !e.navParams.showHidden
+
+	name, _ := e.getSelectedEntry()
+	e.refreshDir(name)
+
+	if e.navParams.showHidden {
+		e.setMessage("hidden files shown")
+		return
+	}
+
+	e.setMessage("hidden files hidden")
+}
+
+func (e *Editor) cycleSortOrder() {
+	e.navParams.sortOrder = (e.navParams.sortOrder + 1) % SORT_ORDERS_COUNT
+
+	name, _ := e.getSelectedEntry()
+	e.refreshDir(name)
+	e.setMessage("sorted by %s", sortOrderNames[e.navParams.sortOrder])
+}
+
+// handle the keys while a name (or a confirmation) is asked for an action
+func (e *Editor) handleNavigationActionEvent(ev tcell.Event) error {
+	evKey, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return nil
+	}
+
+	if e.navParams.action == NAV_ACTION_DELETE {
+		switch {
+		case evKey.Key() == tcell.KeyRune && (evKey.Rune() == 'y' || evKey.Rune() == 'Y'):
+			e.runNavigationAction()
+		case evKey.Key() == tcell.KeyRune || evKey.Key() == tcell.KeyEscape:
+			e.cancelNavigationAction()
+		}
+		return nil
+	}
+
+	switch evKey.Key() {
+	case tcell.KeyEscape:
+		e.cancelNavigationAction()
+	case tcell.KeyEnter:
+		e.runNavigationAction()
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		e.removeCharFromInputBuffer()
+	case tcell.KeyRune:
+		e.insertCharToInputBuffer(evKey.Rune())
+	}
+
+	return nil
+}
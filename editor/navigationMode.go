@@ -1,41 +1,136 @@
 package editor
 
 import (
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+const PARENT_DIR_NAME = ".."
+
+// the entry listed on top of every directory (except the root) to go up
+type parentDirEntry struct{}
+
+func (parentDirEntry) Name() string               { return PARENT_DIR_NAME }
+func (parentDirEntry) IsDir() bool                { return true }
+func (parentDirEntry) Type() fs.FileMode          { return fs.ModeDir }
+func (parentDirEntry) Info() (fs.FileInfo, error) { return nil, fs.ErrNotExist }
+
+// list the directory 'dirName' and make it the current directory of the navigation
 func (e *Editor) openDir(dirName string) error {
-	files, err := os.ReadDir(dirName)
+	dirName, err := filepath.Abs(dirName)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dirName)
 	if err != nil {
 		return err
 	}
 
+	var files []os.DirEntry
+	if filepath.Dir(dirName) != dirName {
+		files = append(files, parentDirEntry{})
+	}
+
+	for _, entry := range entries {
+		if isHiddenFile(entry.Name()) && !e.navParams.showHidden {
+			continue
+		}
+		files = append(files, entry)
+	}
+
+	sortEntries(files, e.navParams.sortOrder)
+
+	e.navParams.dir = dirName
 	e.navParams.files = files
+	e.navParams.currentFileIndex = 0
+	e.navParams.top = 0
 	return nil
 }
 
+// get the directory the navigation starts from when it has not been opened yet
+func (e *Editor) getNavigationStartDir() string {
+	fileInfo, err := os.Stat(e.config.OpenedFile)
+	if err == nil && fileInfo.IsDir() {
+		return e.config.OpenedFile
+	}
+
+	if e.config.CurrentFile != "" {
+		return filepath.Dir(e.config.CurrentFile)
+	}
+
+	return "."
+}
+
+// switch to the navigation mode, the current directory is listed again so the list is never stale
 func (e *Editor) setNavigationMode() {
 	e.mode = NAVIGATION_MODE
-	e.openDir(e.config.OpenedFile)
-	e.navParams.currentFileIndex = 0
+
+	dir := e.navParams.dir
+	if dir == "" {
+		dir = e.getNavigationStartDir()
+	}
+
+	err := e.openDir(dir)
+	if err != nil {
+		e.setMessage("%s", err)
+	}
 }
 
-func (e *Editor) setNavigationModeFromInsertMode() {
-	e.mode = NAVIGATION_MODE
+// select the entry named 'name' if it is listed
+func (e *Editor) selectFileByName(name string) {
+	for i, file := range e.navParams.files {
+		if file.Name() == name {
+			e.navParams.currentFileIndex = i
+			return
+		}
+	}
+}
 
-	e.navParams.currentFileIndex = 0
-	e.navParams.files = nil
+// list the directory 'dir' and report the failure (keeping the current listing) if it can not be read
+func (e *Editor) changeDir(dir string) {
+	err := e.openDir(dir)
+	if err != nil {
+		e.setMessage("%s", err)
+	}
+}
+
+// list the current directory again and select the entry named 'name'
+func (e *Editor) refreshDir(name string) {
+	e.changeDir(e.navParams.dir)
+	e.selectFileByName(name)
+}
 
-	if e.config.OpenedFile == "" {
-		e.enableInputBuffer()
-		e.setInputCurrentBuffer(INPUT_TEXT)
-		e.setInputBufferInputRequestString("filepath: ")
+// go to the parent directory and select the directory we come from
+func (e *Editor) goToParentDir() {
+	current := e.navParams.dir
+	parent := filepath.Dir(current)
+	if parent == current {
 		return
 	}
 
-	e.openDir(e.config.OpenedFile)
+	e.changeDir(parent)
+	if e.navParams.dir == parent {
+		e.selectFileByName(filepath.Base(current))
+	}
+}
+
+// get the path of the current directory as a breadcrumb (root > dir > subdir)
+func (e *Editor) getNavigationBreadcrumb() string {
+	dir := filepath.ToSlash(e.navParams.dir)
+
+	var parts []string
+	for _, part := range strings.Split(dir, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(append([]string{"/"}, parts...), " > ")
 }
 
 func (e *Editor) updateFileIndexCursorDown() {
@@ -63,9 +158,35 @@ func (e *Editor) updateFileIndexCursorUp() {
 }
 
 func (e *Editor) handleEnterKeyInNavigationMode() error {
-	filepath := e.navParams.files[e.navParams.currentFileIndex].Name()
-	e.config.OpenedFile += "/" + filepath
-	return e.loadFileFromConfiguration()
+	if len(e.navParams.files) == 0 {
+		return nil
+	}
+
+	file := e.navParams.files[e.navParams.currentFileIndex]
+	if file.Name() == PARENT_DIR_NAME {
+		e.goToParentDir()
+		return nil
+	}
+
+	path := filepath.Join(e.navParams.dir, file.Name())
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		e.setMessage("%s", err)
+		return nil
+	}
+
+	if fileInfo.IsDir() {
+		e.changeDir(path)
+		return nil
+	}
+
+	err = e.openFile(path)
+	if err != nil {
+		e.setMessage("%s", err)
+	}
+
+	return nil
 }
 
 func (e *Editor) handleRuneKeyInNavigationMode(c rune) {
@@ -78,17 +199,43 @@ func (e *Editor) handleRuneKeyInNavigationMode(c rune) {
 }
 
 func (e *Editor) handleNavigationModeEvent(ev tcell.Event) error {
+	if e.navParams.action != NAV_ACTION_NONE {
+		return e.handleNavigationActionEvent(ev)
+	}
+
 	switch ev := ev.(type) {
+	case *tcell.EventMouse:
+		return e.handleNavigationMouseEvent(ev)
 	case *tcell.EventKey:
 		switch ev.Key() {
 		case tcell.KeyEscape:
-			e.Quit()
+			e.quit()
+		case tcell.KeyCtrlQ:
+			e.quitWithoutSaving()
 		case tcell.KeyDown:
 			e.updateFileIndexCursorDown()
 		case tcell.KeyUp:
 			e.updateFileIndexCursorUp()
 		case tcell.KeyEnter:
-			e.handleEnterKeyInNavigationMode()
+			return e.handleEnterKeyInNavigationMode()
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			e.goToParentDir()
+		case tcell.KeyCtrlF:
+			e.setGrepMode()
+		case tcell.KeyCtrlO:
+			e.setFinderMode()
+		case tcell.KeyCtrlN:
+			e.askNavigationAction(NAV_ACTION_CREATE)
+		case tcell.KeyCtrlR, tcell.KeyF2:
+			e.askNavigationAction(NAV_ACTION_RENAME)
+		case tcell.KeyCtrlD:
+			e.askNavigationAction(NAV_ACTION_DUPLICATE)
+		case tcell.KeyDelete, tcell.KeyCtrlX:
+			e.askNavigationAction(NAV_ACTION_DELETE)
+		case tcell.KeyCtrlT:
+			e.toggleHiddenFiles()
+		case tcell.KeyCtrlS:
+			e.cycleSortOrder()
 		case tcell.KeyRune:
 			e.handleRuneKeyInNavigationMode(ev.Rune())
 			return nil
@@ -0,0 +1,55 @@
+package editor
+
+import "github.com/gdamore/tcell/v2"
+
+// move the cursor and the view of the current window 'pages' pages down (up when negative)
+func (e *Editor) scrollPages(pages int) {
+	page := max(e.window.h-1, 1)
+	offset := pages * page
+	lastLine := e.buffer.count() - 1
+
+	line := min(max(e.realCursor.getLine()+offset, 0), lastLine)
+	e.realCursor = e.buffer.clampLocation(newLocation(line, e.realCursor.getCol()))
+
+	top := min(max(e.renderingCursor.getLine()+offset, 0), lastLine)
+	e.renderingCursor.setLine(top)
+}
+
+func (e *Editor) moveCursorToTop() {
+	e.realCursor = newLocation(0, 0)
+}
+
+func (e *Editor) moveCursorToBottom() {
+	e.realCursor = newLocation(e.buffer.count()-1, 0)
+}
+
+// handle the keys of a pager in the documents that can not be changed:
+// Space and b for a page down and up, g and G for the top and the bottom, / to search and q to quit
+func (e *Editor) handlePagerKeyEvent(ev *tcell.EventKey) bool {
+	if !e.isReadOnly(e.getCurrentDocument()) || e.inputBufferIsEnabled() {
+		return false
+	}
+
+	if ev.Key() != tcell.KeyRune || ev.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return false
+	}
+
+	switch ev.Rune() {
+	case ' ':
+		e.scrollPages(1)
+	case 'b':
+		e.scrollPages(-1)
+	case 'g':
+		e.moveCursorToTop()
+	case 'G':
+		e.moveCursorToBottom()
+	case '/':
+		e.setSearchMode()
+	case 'q':
+		e.quit()
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package editor
+
+import "os"
+
+// check if a process with the pid 'pid' is running
+func processIsRunning(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
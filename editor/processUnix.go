@@ -0,0 +1,11 @@
+//go:build unix
+
+package editor
+
+import "syscall"
+
+// check if a process with the pid 'pid' is running (a signal 0 only checks that it can be sent)
+func processIsRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,38 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// get the line of a result once the match is replaced
+func replacedLine(result GrepResult, text, replacement string) string {
+	return result.content[:result.rawCol] + replacement + result.content[result.rawCol+len(text):]
+}
+
+// replace the matches of 'text' found in the file at 'path' by a search, the matches are sorted by line and column,
+// nothing is written if the file changed since the search
+func replaceInFile(path string, matches []GrepResult, text, replacement string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	for _, match := range matches {
+		if match.line >= len(lines) || strings.TrimRight(lines[match.line], "\r") != match.content {
+			return fmt.Errorf("the file changed since the search")
+		}
+	}
+
+	// from the last match to the first one so the columns of the remaining ones stay valid
+	for i := len(matches) - 1; i >= 0; i-- {
+		match := matches[i]
+		line := lines[match.line]
+		lines[match.line] = line[:match.rawCol] + replacement + line[match.rawCol+len(text):]
+	}
+
+	return writeFileAtomically(path, []byte(strings.Join(lines, "\n")))
+}
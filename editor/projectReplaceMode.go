@@ -0,0 +1,221 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	PREVIEW_ROW_FILE = iota
+	PREVIEW_ROW_OLD
+	PREVIEW_ROW_NEW
+	PREVIEW_ROW_REPORT
+)
+
+// a row of the preview of the replace in files
+type previewRow struct {
+	kind   int
+	text   string
+	result int // index of the search result the row belongs to
+}
+
+func (e *Editor) setProjectReplaceMode() {
+	e.projectReplaceParams = EditorProjectReplaceParams{
+		replacement: e.input.buffers[NEW_TEXT],
+		included:    make([]bool, len(e.grepParams.results)),
+	}
+
+	for i := range e.projectReplaceParams.included {
+		e.projectReplaceParams.included[i] = true
+	}
+
+	e.mode = PROJECT_REPLACE_MODE
+	e.disableInputBuffer()
+}
+
+// get back to the results of the search, with the replacement still typed
+func (e *Editor) switchToGrepFromProjectReplaceMode() {
+	e.mode = GREP_MODE
+	e.input.enabled = true
+	e.setInputCurrentBuffer(NEW_TEXT)
+	e.input.buffers[NEW_TEXT] = e.projectReplaceParams.replacement
+}
+
+func (e *Editor) switchToNavigationFromProjectReplaceMode() {
+	e.projectReplaceParams = EditorProjectReplaceParams{}
+	e.grepParams.results = nil
+	e.resetInput()
+	e.setNavigationMode()
+}
+
+func (e *Editor) countIncludedMatches() int {
+	count := 0
+	for _, included := range e.projectReplaceParams.included {
+		if included {
+			count++
+		}
+	}
+
+	return count
+}
+
+// get the index of the first and the last result (not included) of the file of the result 'index'
+func (e *Editor) fileResultsBounds(index int) (int, int) {
+	results := e.grepParams.results
+	path := results[index].path
+
+	start := index
+	for start > 0 && results[start-1].path == path {
+		start--
+	}
+
+	end := index
+	for end < len(results) && results[end].path == path {
+		end++
+	}
+
+	return start, end
+}
+
+// include or exclude the selected match
+func (e *Editor) toggleCurrentMatch() {
+	current := e.projectReplaceParams.current
+	e.projectReplaceParams.included[current] = !e.projectReplaceParams.included[current]
+}
+
+// include or exclude all the matches of the file of the selected match
+func (e *Editor) toggleCurrentFileMatches() {
+	current := e.projectReplaceParams.current
+	included := !e.projectReplaceParams.included[current]
+
+	start, end := e.fileResultsBounds(current)
+	for i := start; i < end; i++ {
+		e.projectReplaceParams.included[i] = included
+	}
+}
+
+func (e *Editor) updateProjectReplaceIndexCursorDown() {
+	if e.projectReplaceParams.current < len(e.grepParams.results)-1 {
+		e.projectReplaceParams.current++
+	}
+}
+
+func (e *Editor) updateProjectReplaceIndexCursorUp() {
+	if e.projectReplaceParams.current > 0 {
+		e.projectReplaceParams.current--
+	}
+}
+
+// replace the included matches file by file and keep a report of what happened to every file
+func (e *Editor) applyProjectReplace() {
+	results := e.grepParams.results
+	params := &e.projectReplaceParams
+	text := e.grepParams.query
+
+	params.report = nil
+	modified := 0
+
+	for start := 0; start < len(results); {
+		_, end := e.fileResultsBounds(start)
+
+		var matches []GrepResult
+		for i := start; i < end; i++ {
+			if params.included[i] {
+				matches = append(matches, results[i])
+			}
+		}
+
+		if len(matches) != 0 {
+			path := results[start].path
+			err := replaceInFile(path, matches, text, params.replacement)
+			if err != nil {
+				params.report = append(params.report, fmt.Sprintf("failed: %s: %s", e.relativeToGrepRoot(path), err))
+			} else {
+				params.report = append(params.report, fmt.Sprintf("modified: %s (%d replacements)", e.relativeToGrepRoot(path), len(matches)))
+				modified++
+			}
+		}
+
+		start = end
+	}
+
+	params.applied = true
+	params.top = 0
+	e.setMessage("modified %d files, press any key to get back to the navigation", modified)
+}
+
+// get the rows of the preview: a header for every file, then the old and the new line of every match
+func (e *Editor) projectReplacePreviewRows() []previewRow {
+	var rows []previewRow
+
+	if e.projectReplaceParams.applied {
+		for _, line := range e.projectReplaceParams.report {
+			rows = append(rows, previewRow{kind: PREVIEW_ROW_REPORT, text: line, result: -1})
+		}
+
+		return rows
+	}
+
+	results := e.grepParams.results
+	for i, result := range results {
+		if i == 0 || results[i-1].path != result.path {
+			start, end := e.fileResultsBounds(i)
+			header := fmt.Sprintf("%s (%d matches)", e.relativeToGrepRoot(result.path), end-start)
+			rows = append(rows, previewRow{kind: PREVIEW_ROW_FILE, text: header, result: i})
+		}
+
+		marker := "[ ]"
+		if e.projectReplaceParams.included[i] {
+			marker = "[x]"
+		}
+
+		prefix := fmt.Sprintf("%s %5d ", marker, result.line+1)
+		newLine := replacedLine(result, e.grepParams.query, e.projectReplaceParams.replacement)
+
+		rows = append(rows, previewRow{kind: PREVIEW_ROW_OLD, text: prefix + "- " + expandTabs(result.content), result: i})
+		rows = append(rows, previewRow{kind: PREVIEW_ROW_NEW, text: fmt.Sprintf("%*s+ %s", len(prefix), "", expandTabs(newLine)), result: i})
+	}
+
+	return rows
+}
+
+func (e *Editor) projectReplaceStatus() string {
+	if e.projectReplaceParams.applied {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%d matches included (space: toggle, tab: toggle file, enter: apply)", e.countIncludedMatches(), len(e.grepParams.results))
+}
+
+// handle the keys of the preview of the replace in files
+func (e *Editor) handleProjectReplaceModeEvent(ev tcell.Event) error {
+	evKey, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return nil
+	}
+
+	if e.projectReplaceParams.applied {
+		e.switchToNavigationFromProjectReplaceMode()
+		return nil
+	}
+
+	switch evKey.Key() {
+	case tcell.KeyEscape:
+		e.switchToGrepFromProjectReplaceMode()
+	case tcell.KeyEnter:
+		e.applyProjectReplace()
+	case tcell.KeyDown:
+		e.updateProjectReplaceIndexCursorDown()
+	case tcell.KeyUp:
+		e.updateProjectReplaceIndexCursorUp()
+	case tcell.KeyTab:
+		e.toggleCurrentFileMatches()
+	case tcell.KeyRune:
+		if evKey.Rune() == ' ' {
+			e.toggleCurrentMatch()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package editor
+
+import "github.com/gdamore/tcell/v2"
+
+// a question waiting for a one char answer, it is asked on top of any mode
+type EditorQuestion struct {
+	text     string
+	onAnswer func(answer rune) error // called with 0 when the question is cancelled (escape)
+}
+
+// ask a question, the answer is given to 'onAnswer' once typed,
+// it waits for the pending questions to be answered first
+func (editor *Editor) askQuestion(text string, onAnswer func(answer rune) error) {
+	question := &EditorQuestion{
+		text:     text,
+		onAnswer: onAnswer,
+	}
+
+	if editor.question != nil {
+		editor.nextQuestions = append(editor.nextQuestions, question)
+		return
+	}
+
+	editor.question = question
+}
+
+func (editor *Editor) hasQuestion() bool {
+	return editor.question != nil
+}
+
+// give the typed answer to the pending question
+func (editor *Editor) handleQuestionEvent(ev tcell.Event) error {
+	evKey, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return nil
+	}
+
+	var answer rune
+	switch evKey.Key() {
+	case tcell.KeyEscape:
+		answer = 0
+	case tcell.KeyRune:
+		answer = evKey.Rune()
+	default:
+		return nil
+	}
+
+	question := editor.question
+	editor.question = nil
+	err := question.onAnswer(answer)
+
+	// the answer may have asked another question, it comes before the waiting ones
+	if editor.question == nil && len(editor.nextQuestions) > 0 {
+		editor.question = editor.nextQuestions[0]
+		editor.nextQuestions = editor.nextQuestions[1:]
+	}
+
+	return err
+}
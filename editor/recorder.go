@@ -0,0 +1,278 @@
+package editor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const REPLAY_MAX_DELAY = 2 * time.Second // the long pauses of a recording are shortened when it is replayed
+
+// types of the recorded events
+const (
+	RECORD_START  = "start" // first record, with the size of the terminal
+	RECORD_KEY    = "key"
+	RECORD_MOUSE  = "mouse"
+	RECORD_RESIZE = "resize"
+	RECORD_PASTE  = "paste"
+	RECORD_FOCUS  = "focus"
+)
+
+// an event of the terminal as it is written in a recording, one JSON object by line
+type EventRecord struct {
+	Time    int64            `json:"time"` // milliseconds since the start of the recording
+	Type    string           `json:"type"`
+	Width   int              `json:"width,omitempty"`
+	Height  int              `json:"height,omitempty"`
+	Key     tcell.Key        `json:"key,omitempty"`
+	Rune    rune             `json:"rune,omitempty"`
+	Mod     tcell.ModMask    `json:"mod,omitempty"`
+	X       int              `json:"x,omitempty"`
+	Y       int              `json:"y,omitempty"`
+	Buttons tcell.ButtonMask `json:"buttons,omitempty"`
+	Start   bool             `json:"start,omitempty"` // start of a paste, its end otherwise
+	Focused bool             `json:"focused,omitempty"`
+}
+
+// event posted once all the events of a recording are replayed
+type EventReplayEnd struct {
+	tcell.EventTime
+}
+
+type EditorRecorder struct {
+	file    *os.File // nil when nothing is recorded
+	encoder *json.Encoder
+	start   time.Time
+}
+
+type EditorReplay struct {
+	path   string
+	cancel chan struct{} // closed to stop posting the events
+}
+
+// get the record of an event of the terminal, the events of the editor itself (ticks, results of the searches...)
+// are not recorded as they come again when the recording is replayed
+func newEventRecord(ev tcell.Event) (EventRecord, bool) {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		return EventRecord{Type: RECORD_KEY, Key: ev.Key(), Rune: ev.Rune(), Mod: ev.Modifiers()}, true
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		return EventRecord{Type: RECORD_MOUSE, X: x, Y: y, Buttons: ev.Buttons(), Mod: ev.Modifiers()}, true
+	case *tcell.EventResize:
+		w, h := ev.Size()
+		return EventRecord{Type: RECORD_RESIZE, Width: w, Height: h}, true
+	case *tcell.EventPaste:
+		return EventRecord{Type: RECORD_PASTE, Start: ev.Start()}, true
+	case *tcell.EventFocus:
+		return EventRecord{Type: RECORD_FOCUS, Focused: ev.Focused}, true
+	}
+
+	return EventRecord{}, false
+}
+
+// get the event written in the record
+func (record EventRecord) event() (tcell.Event, error) {
+	switch record.Type {
+	case RECORD_KEY:
+		return tcell.NewEventKey(record.Key, record.Rune, record.Mod), nil
+	case RECORD_MOUSE:
+		return tcell.NewEventMouse(record.X, record.Y, record.Buttons, record.Mod), nil
+	case RECORD_RESIZE:
+		return tcell.NewEventResize(record.Width, record.Height), nil
+	case RECORD_PASTE:
+		return tcell.NewEventPaste(record.Start), nil
+	case RECORD_FOCUS:
+		return tcell.NewEventFocus(record.Focused), nil
+	}
+
+	return nil, fmt.Errorf("unknown event type %q", record.Type)
+}
+
+// write the events of the terminal into the file at 'path', starting with the size of the terminal
+func (e *Editor) startRecording(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	e.recorder = EditorRecorder{file: file, encoder: json.NewEncoder(file), start: time.Now()}
+
+	w, h := e.screen.Size()
+	return e.writeRecord(EventRecord{Type: RECORD_START, Width: w, Height: h})
+}
+
+func (e *Editor) stopRecording() {
+	if e.recorder.file == nil {
+		return
+	}
+
+	e.recorder.file.Close()
+	e.recorder = EditorRecorder{}
+}
+
+// write the record right away, so the recording is complete even when the editor crashes
+func (e *Editor) writeRecord(record EventRecord) error {
+	record.Time = time.Since(e.recorder.start).Milliseconds()
+	return e.recorder.encoder.Encode(record)
+}
+
+func (e *Editor) recordEvent(ev tcell.Event) error {
+	if e.recorder.file == nil {
+		return nil
+	}
+
+	record, ok := newEventRecord(ev)
+	if !ok {
+		return nil
+	}
+
+	return e.writeRecord(record)
+}
+
+// read the records of a recording, the first one gives the size of the terminal
+func readRecording(r io.Reader) ([]EventRecord, error) {
+	var records []EventRecord
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record EventRecord
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", line, err)
+		}
+
+		if (len(records) == 0) != (record.Type == RECORD_START) {
+			return nil, fmt.Errorf("%d: the recording must start with one %q record", line, RECORD_START)
+		}
+
+		if record.Type != RECORD_START {
+			_, err = record.event()
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", line, err)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the recording is empty")
+	}
+
+	return records, nil
+}
+
+// post the events of the recording at 'path' with the delays they were recorded with, as if they were typed again;
+// a simulated screen takes the size of the recorded terminal
+func (e *Editor) startReplay(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := readRecording(file)
+	if err != nil {
+		return fmt.Errorf("%s:%w", path, err)
+	}
+
+	simulation, simulated := e.screen.(tcell.SimulationScreen)
+	if simulated {
+		simulation.SetSize(records[0].Width, records[0].Height)
+	}
+
+	cancel := make(chan struct{})
+	e.replay = EditorReplay{path: path, cancel: cancel}
+
+	screen := e.screen
+	go func() {
+		// like a terminal, a simulated screen tells its size first
+		if simulated {
+			w, h := simulation.Size()
+			postEvent(screen, tcell.NewEventResize(w, h), cancel)
+		}
+
+		previous := records[0].Time
+		for _, record := range records[1:] {
+			delay := time.Duration(record.Time-previous) * time.Millisecond
+			previous = record.Time
+
+			select {
+			case <-cancel:
+				return
+			case <-time.After(min(delay, REPLAY_MAX_DELAY)):
+			}
+
+			// the terminal is resized before it tells it
+			if record.Type == RECORD_RESIZE && simulated {
+				simulation.SetSize(record.Width, record.Height)
+			}
+
+			ev, _ := record.event()
+			postEvent(screen, ev, cancel)
+		}
+
+		ev := &EventReplayEnd{}
+		ev.SetEventNow()
+		postEvent(screen, ev, cancel)
+	}()
+
+	return nil
+}
+
+func (e *Editor) stopReplay() {
+	if e.replay.cancel == nil {
+		return
+	}
+
+	close(e.replay.cancel)
+	e.replay = EditorReplay{}
+}
+
+// the replay without a terminal ends with the recording, it goes on with the keyboard otherwise
+func (e *Editor) finishReplay() {
+	if e.config.Headless {
+		e.quitWithoutSaving()
+		return
+	}
+
+	e.setMessage("end of the replay of %s", e.replay.path)
+}
+
+// write the text shown on the screen, without the spaces at the end of the rows
+func (e *Editor) WriteScreen(w io.Writer) error {
+	width, height := e.screen.Size()
+
+	for y := 0; y < height; y++ {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			c, _, _, _ := e.screen.GetContent(x, y)
+			if c == 0 {
+				c = ' '
+			}
+			row.WriteRune(c)
+		}
+
+		_, err := fmt.Fprintln(w, strings.TrimRight(row.String(), " "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,27 +1,45 @@
 package editor
 
 import (
+	"fmt"
 	"math"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
 )
 
 func (e *Editor) renderLineInInsertMode(lineIndex int, row int) {
 	line := e.buffer.lines[lineIndex]
+	left := e.window.x - e.renderingCursor.getCol()
+	row += e.window.y
 
 	for i, c := range line.getContent() {
-		e.screen.SetContent(i, row, c, nil, tcell.StyleDefault)
+		if i < e.renderingCursor.getCol() {
+			continue
+		}
+
+		if i >= e.renderingCursor.getCol()+e.window.w {
+			break
+		}
+
+		e.screen.SetContent(left+i, row, c, nil, tcell.StyleDefault)
 	}
 }
 
 func (e *Editor) renderLineInSearchMode(lineIndex int, row int) {
 	style := tcell.StyleDefault.Bold(true).Underline(true).Background(tcell.ColorDarkCyan)
 	line := e.buffer.lines[lineIndex]
+	left := e.window.x - e.renderingCursor.getCol()
+	row += e.window.y
 
 	count := 0
 
 	for i, c := range line.getContent() {
+		if i >= e.renderingCursor.getCol()+e.window.w {
+			break
+		}
+
 		currentLocation := newLocation(lineIndex, i)
 		found := e.lookupLocationInSearchLocations(currentLocation)
 
@@ -29,33 +47,57 @@ func (e *Editor) renderLineInSearchMode(lineIndex int, row int) {
 			count = len(e.input.buffers[INPUT_TEXT])
 		}
 
+		cellStyle := tcell.StyleDefault
 		if count > 0 {
-			e.screen.SetContent(i, row, c, nil, style)
+			cellStyle = style
 			count--
-			continue
 		}
 
-		e.screen.SetContent(i, row, c, nil, tcell.StyleDefault)
+		// a match starting on the left of the window is highlighted from its first column
+		if i >= e.renderingCursor.getCol() {
+			e.screen.SetContent(left+i, row, c, nil, cellStyle)
+		}
 	}
 }
 
 func (e *Editor) renderLineInSelectionMode(lineIndex int, row int) {
 	style := tcell.StyleDefault.Background(tcell.ColorBlue)
 	line := e.buffer.lines[lineIndex]
+	left := e.window.x - e.renderingCursor.getCol()
+	row += e.window.y
 
 	for i, c := range line.getContent() {
+		if i < e.renderingCursor.getCol() {
+			continue
+		}
+
+		if i >= e.renderingCursor.getCol()+e.window.w {
+			break
+		}
+
 		currentLocation := newLocation(lineIndex, i)
 		if e.checkLocationInSelectionModeBounds(currentLocation) {
-			e.screen.SetContent(i, row, c, nil, style)
+			e.screen.SetContent(left+i, row, c, nil, style)
 			continue
 		}
 
-		e.screen.SetContent(i, row, c, nil, tcell.StyleDefault)
+		e.screen.SetContent(left+i, row, c, nil, tcell.StyleDefault)
 	}
 }
 
+// get the first and the last row the cursor can be on without scrolling the current window
+func (e *Editor) getCursorRowBounds() (upper, bottom int) {
+	h := e.window.h
+	bottom = max(h-BOTTOM_CURSOR_BOUNDS, (h-1)/2)
+	upper = min(UPPER_CURSOR_BOUNDS, bottom)
+
+	return upper, bottom
+}
+
 func (e *Editor) updateRenderingCursor() {
-	for e.realCursor.getLine() < e.renderingCursor.getLine()+UPPER_CURSOR_BOUNDS {
+	upper, bottom := e.getCursorRowBounds()
+
+	for e.realCursor.getLine() < e.renderingCursor.getLine()+upper {
 		e.renderingCursor.setLine(e.renderingCursor.getLine() - 1)
 		if e.renderingCursor.getLine() < 0 {
 			e.renderingCursor.setLine(0)
@@ -63,11 +105,24 @@ func (e *Editor) updateRenderingCursor() {
 		}
 	}
 
-	_, h := e.screen.Size()
-	h -= BOTTOM_CURSOR_BOUNDS
-	for e.realCursor.getLine() > e.renderingCursor.getLine()+h-BOTTOM_CURSOR_BOUNDS {
+	for e.realCursor.getLine() > e.renderingCursor.getLine()+bottom {
 		e.renderingCursor.setLine(e.renderingCursor.getLine() + 1)
 	}
+
+	e.renderingCursor.setCol(getScrolledCol(e.renderingCursor.getCol(), e.realCursor.getCol(), e.window.w))
+}
+
+// get the first rendered column of a window of 'width' columns so that the column 'col' of the cursor is in it
+func getScrolledCol(first, col, width int) int {
+	if col < first {
+		return col
+	}
+
+	if col >= first+width {
+		return max(col-width+1, 0)
+	}
+
+	return first
 }
 
 func (e *Editor) updateRelativeCursor() {
@@ -75,10 +130,7 @@ func (e *Editor) updateRelativeCursor() {
 }
 
 func (e *Editor) getNumberLinesToRender() int {
-	_, h := e.screen.Size()
-	h -= BOTTOM_CURSOR_BOUNDS
-
-	return int(math.Min(float64(h), float64(e.buffer.count()-e.renderingCursor.getLine())))
+	return int(math.Min(float64(e.window.h), float64(e.buffer.count()-e.renderingCursor.getLine())))
 }
 
 // render the content of the e buffer in the normal mode
@@ -104,6 +156,91 @@ func (e *Editor) renderContentInSelectionMode() {
 	}
 }
 
+// render the windows the cursor is not in and the separators between the windows,
+// the current one is rendered by the mode
+func (e *Editor) renderWindows() {
+	e.placeWindows()
+
+	for _, win := range e.layout.windows() {
+		if win != e.window {
+			e.renderWindow(win)
+		}
+	}
+
+	e.renderWindowSeparators(e.layout)
+}
+
+// render a window the cursor is not in
+func (e *Editor) renderWindow(win *Window) {
+	buffer := win.document.buffer
+	win.realCursor = buffer.clampLocation(win.realCursor)
+	win.renderingCursor = buffer.clampLocation(win.renderingCursor)
+
+	// keep the cursor of the window in it (it may have been resized)
+	if win.realCursor.getLine() >= win.renderingCursor.getLine()+win.h {
+		win.renderingCursor.setLine(win.realCursor.getLine() - win.h + 1)
+	}
+
+	if win.realCursor.getLine() < win.renderingCursor.getLine() {
+		win.renderingCursor.setLine(win.realCursor.getLine())
+	}
+
+	first := getScrolledCol(win.renderingCursor.getCol(), win.realCursor.getCol(), win.w)
+	win.renderingCursor.setCol(first)
+
+	for row := 0; row < win.h && win.renderingCursor.getLine()+row < buffer.count(); row++ {
+		content := buffer.lines[win.renderingCursor.getLine()+row].getContent()
+		if first >= len(content) {
+			continue
+		}
+
+		e.renderClippedTextOnStyle(win.y+row, win.x, win.w, content[first:], tcell.StyleDefault)
+	}
+}
+
+func (e *Editor) renderWindowSeparators(layout *Layout) {
+	if layout.window != nil {
+		return
+	}
+
+	first := layout.children[0]
+	if layout.vertical {
+		for row := layout.y; row < layout.y+layout.h; row++ {
+			e.screen.SetContent(first.x+first.w, row, tcell.RuneVLine, nil, tcell.StyleDefault)
+		}
+	} else {
+		for col := layout.x; col < layout.x+layout.w; col++ {
+			e.screen.SetContent(col, first.y+first.h, tcell.RuneHLine, nil, tcell.StyleDefault)
+		}
+	}
+
+	e.renderWindowSeparators(layout.children[0])
+	e.renderWindowSeparators(layout.children[1])
+}
+
+// render the mode the finder was opened from under its box
+func (e *Editor) renderFinderBackground() {
+	if e.finderParams.previousMode == NAVIGATION_MODE {
+		e.renderNavigation()
+		return
+	}
+
+	e.renderTextBackground()
+}
+
+// render the buffer (and the sidebar) under a box drawn over them
+func (e *Editor) renderTextBackground() {
+	if e.sidebar.visible {
+		e.renderSidebar()
+	}
+
+	e.renderTabs()
+	e.renderWindows()
+
+	e.updateRenderingCursor()
+	e.renderContentInInsertMode()
+}
+
 // render the content of the e buffer
 func (e *Editor) renderContent() {
 	e.updateRenderingCursor()
@@ -121,58 +258,412 @@ func (e *Editor) renderContent() {
 // render the cursor of the e (real Cursor)
 func (e *Editor) renderCursor() {
 	e.updateRelativeCursor()
-	e.screen.ShowCursor(e.relativeCursor.getCol(), e.relativeCursor.getLine())
+
+	if e.sidebar.focused {
+		e.screen.HideCursor()
+		return
+	}
+
+	e.screen.ShowCursor(e.window.x+e.relativeCursor.getCol(), e.window.y+e.relativeCursor.getLine())
 }
 
 func (e *Editor) renderTextOnStyle(line, col int, text string, style tcell.Style) {
-	for i, c := range text {
+	for i, c := range []rune(text) {
 		e.screen.SetContent(col+i, line, c, nil, style)
 	}
 }
 
+// render a text cut to 'width' cells
+func (e *Editor) renderClippedTextOnStyle(line, col, width int, text string, style tcell.Style) {
+	runes := []rune(text)
+	if len(runes) > width {
+		runes = runes[:width]
+	}
+
+	e.renderTextOnStyle(line, col, string(runes), style)
+}
+
 // render any text to the e screen (helper function)
 func (e *Editor) renderText(line, col int, text string) {
 	e.renderTextOnStyle(line, col, text, tcell.StyleDefault)
 }
 
+// get the row of the questions, the messages and the inputs
+func (e *Editor) getPromptRow() int {
+	_, h := e.screen.Size()
+	return h - PROMPT_SCREEN_ROW_FROM_BOTTOM
+}
+
+// get the row of the name of the buffer and of the cursor position
+func (e *Editor) getStatusRow() int {
+	_, h := e.screen.Size()
+	return h - LINE_CELL_ROW_FROM_BOTTOM
+}
+
 // render information (mode, cursor)
 func (e *Editor) renderInfo() {
-	lineString := strconv.Itoa(e.realCursor.getLine())
-	colString := strconv.Itoa(e.realCursor.getCol())
+	// the cursor position means nothing in the results of the search in files
+	if e.mode != GREP_MODE && e.mode != PROJECT_REPLACE_MODE {
+		lineString := strconv.Itoa(e.realCursor.getLine())
+		colString := strconv.Itoa(e.realCursor.getCol())
+
+		e.renderText(e.getStatusRow(), LINE_CELL_COL, lineString)
+		e.renderText(e.getStatusRow(), LINE_CELL_COL+len(lineString), ":")
+		e.renderText(e.getStatusRow(), LINE_CELL_COL+len(lineString)+1, colString)
+	}
+
+	if e.macros.recording != 0 {
+		e.renderText(e.getStatusRow(), LINE_CELL_COL+LINE_CELL_COL/2, fmt.Sprintf("[recording %c]", e.macros.recording))
+	}
 
-	e.renderText(LINE_CELL_ROW, LINE_CELL_COL, lineString)
-	e.renderText(LINE_CELL_ROW, LINE_CELL_COL+len(lineString), ":")
-	e.renderText(LINE_CELL_ROW, LINE_CELL_COL+len(lineString)+1, colString)
+	// the name of the current buffer, with its place among the opened ones
+	if e.mode != GREP_MODE && e.mode != PROJECT_REPLACE_MODE && e.mode != NAVIGATION_MODE {
+		name := e.getCurrentDocument().getName()
+		if e.buffer.modified {
+			name += " [+]"
+		}
+
+		if e.getCurrentDocument().following {
+			name += " [follow]"
+		} else if e.config.ReadOnly {
+			name += " [read-only]"
+		}
 
-	if e.inputBufferIsEnabled() {
+		if len(e.documents) > 1 {
+			name += fmt.Sprintf(" [%d/%d]", e.currentDocument+1, len(e.documents))
+		}
+
+		e.renderClippedTextOnStyle(e.getStatusRow(), 0, LINE_CELL_COL-2, name, tcell.StyleDefault)
+	}
+
+	if e.hasQuestion() {
+		e.renderText(e.getPromptRow(), PROMPT_SCREEN_COL_BEGIN, e.question.text)
+		return
+	}
+
+	if e.message != "" {
+		e.renderText(e.getPromptRow(), PROMPT_SCREEN_COL_BEGIN, e.message)
+		return
+	}
+
+	// the finder renders its input inside its box
+	if e.inputBufferIsEnabled() && e.mode != FINDER_MODE {
 		textToRender := e.input.req + e.input.buffers[e.getInputCurrentBuffer()]
-		e.renderText(PROMPT_SCREEN_LINE_BEGIN+1, PROMPT_SCREEN_COL_BEGIN, textToRender)
+		e.renderText(e.getPromptRow(), PROMPT_SCREEN_COL_BEGIN, textToRender)
 	}
 }
 
+// get the number of entries the navigator shows, under its breadcrumb
+func (e *Editor) getNavigationListHeight() int {
+	_, h := e.screen.Size()
+	return h - BOTTOM_CURSOR_BOUNDS - 1
+}
+
+// render the breadcrumb of the current directory then its entries, scrolled so the selected one is visible
 func (e *Editor) renderNavigation() {
-	for i, file := range e.navParams.files {
+	e.renderTextOnStyle(0, 0, e.getNavigationBreadcrumb(), tcell.StyleDefault.Bold(true))
+
+	h := e.getNavigationListHeight()
+
+	if e.navParams.currentFileIndex < e.navParams.top {
+		e.navParams.top = e.navParams.currentFileIndex
+	}
+
+	if e.navParams.currentFileIndex >= e.navParams.top+h {
+		e.navParams.top = e.navParams.currentFileIndex - h + 1
+	}
+
+	for i := 0; i < h && e.navParams.top+i < len(e.navParams.files); i++ {
+		index := e.navParams.top + i
+		file := e.navParams.files[index]
 		style := tcell.StyleDefault
 
-		if i == e.navParams.currentFileIndex {
+		if index == e.navParams.currentFileIndex {
 			style = style.Background(tcell.ColorGray)
 			if file.IsDir() {
 				style = style.Background(tcell.ColorDarkCyan)
 			}
 		}
 
-		e.renderTextOnStyle(i, 0, e.config.OpenedFile+"/"+file.Name(), style)
+		name := file.Name()
+		if file.IsDir() {
+			name += "/"
+		}
+
+		e.renderTextOnStyle(i+1, 0, name, style)
 	}
 }
 
+// render the results of the search in files, scrolled so the selected one is visible
+func (e *Editor) renderGrepResults() {
+	_, h := e.screen.Size()
+	h -= BOTTOM_CURSOR_BOUNDS
+
+	if e.grepParams.current < e.grepParams.top {
+		e.grepParams.top = e.grepParams.current
+	}
+
+	if e.grepParams.current >= e.grepParams.top+h {
+		e.grepParams.top = e.grepParams.current - h + 1
+	}
+
+	for i := 0; i < h && e.grepParams.top+i < len(e.grepParams.results); i++ {
+		index := e.grepParams.top + i
+
+		style := tcell.StyleDefault
+		if index == e.grepParams.current {
+			style = style.Background(tcell.ColorGray)
+		}
+
+		e.renderTextOnStyle(i, 0, e.grepResultText(e.grepParams.results[index]), style)
+	}
+
+	e.renderText(e.getStatusRow(), 0, e.grepStatus())
+}
+
+func previewRowStyle(row previewRow, selected bool) tcell.Style {
+	style := tcell.StyleDefault
+
+	switch row.kind {
+	case PREVIEW_ROW_FILE:
+		style = style.Bold(true)
+	case PREVIEW_ROW_OLD:
+		style = style.Foreground(tcell.ColorRed)
+	case PREVIEW_ROW_NEW:
+		style = style.Foreground(tcell.ColorGreen)
+	}
+
+	if selected {
+		style = style.Background(tcell.ColorGray)
+	}
+
+	return style
+}
+
+// render the preview of the replace in files, scrolled so the selected match is visible
+func (e *Editor) renderProjectReplacePreview() {
+	_, h := e.screen.Size()
+	h -= BOTTOM_CURSOR_BOUNDS
+
+	rows := e.projectReplacePreviewRows()
+	params := &e.projectReplaceParams
+
+	for i, row := range rows {
+		if row.kind != PREVIEW_ROW_OLD || row.result != params.current {
+			continue
+		}
+
+		// keep the header of the file visible with the first match
+		first := i
+		if i > 0 && rows[i-1].kind == PREVIEW_ROW_FILE {
+			first = i - 1
+		}
+
+		if first < params.top {
+			params.top = first
+		}
+
+		if i+1 >= params.top+h {
+			params.top = i + 2 - h
+		}
+		break
+	}
+
+	for i := 0; i < h && params.top+i < len(rows); i++ {
+		row := rows[params.top+i]
+		selected := !params.applied && row.kind != PREVIEW_ROW_FILE && row.result == params.current
+		e.renderTextOnStyle(i, 0, row.text, previewRowStyle(row, selected))
+	}
+
+	e.renderText(e.getStatusRow(), 0, e.projectReplaceStatus())
+}
+
+// render the tree of the sidebar on the left of the text area, scrolled so the selected node is visible
+func (e *Editor) renderSidebar() {
+	_, h := e.screen.Size()
+	h -= BOTTOM_CURSOR_BOUNDS
+
+	nodes := e.sidebar.root.visibleNodes()
+
+	if e.sidebar.current < e.sidebar.top {
+		e.sidebar.top = e.sidebar.current
+	}
+
+	if e.sidebar.current >= e.sidebar.top+h {
+		e.sidebar.top = e.sidebar.current - h + 1
+	}
+
+	for i := 0; i < h; i++ {
+		e.screen.SetContent(SIDEBAR_WIDTH, i, tcell.RuneVLine, nil, tcell.StyleDefault)
+
+		index := e.sidebar.top + i
+		if index >= len(nodes) {
+			continue
+		}
+
+		node := nodes[index]
+		style := tcell.StyleDefault
+		if node.isDir {
+			style = style.Foreground(tcell.ColorDarkCyan)
+		}
+
+		if node.path == e.config.CurrentFile {
+			style = style.Bold(true)
+		}
+
+		if index == e.sidebar.current && e.sidebar.focused {
+			style = style.Background(tcell.ColorGray)
+		}
+
+		e.renderClippedTextOnStyle(i, 0, SIDEBAR_WIDTH, node.sidebarText(), style)
+	}
+}
+
+// get the position and the size of the box of the finder (centered at the top of the screen)
+func (e *Editor) getFinderBox() (x, y, w, h int) {
+	screenWidth, screenHeight := e.screen.Size()
+
+	w = min(screenWidth-2*FINDER_BOX_MARGIN, FINDER_BOX_MAX_WIDTH)
+	h = min(screenHeight-2*FINDER_BOX_MARGIN, FINDER_BOX_MAX_HEIGHT)
+	x = (screenWidth - w) / 2
+	y = FINDER_BOX_MARGIN
+
+	return x, y, w, h
+}
+
+// clear the area of a box drawn over the text and get its style
+func (e *Editor) renderBox(x, y, w, h int) tcell.Style {
+	boxStyle := tcell.StyleDefault.Background(tcell.ColorBlack)
+	for row := y; row < y+h; row++ {
+		for col := x; col < x+w; col++ {
+			e.screen.SetContent(col, row, ' ', nil, boxStyle)
+		}
+	}
+
+	return boxStyle
+}
+
+// render the finder box: the typed pattern, the matches with their matched chars highlighted, then the status
+func (e *Editor) renderFinder() {
+	x, y, w, h := e.getFinderBox()
+	if w <= 2 || h <= 4 {
+		return
+	}
+
+	boxStyle := e.renderBox(x, y, w, h)
+
+	e.renderClippedTextOnStyle(y, x+1, w-2, e.input.req+e.input.buffers[INPUT_TEXT], boxStyle.Bold(true))
+
+	rows := h - 3
+	params := &e.finderParams
+
+	if params.current < params.top {
+		params.top = params.current
+	}
+
+	if params.current >= params.top+rows {
+		params.top = params.current - rows + 1
+	}
+
+	for i := 0; i < rows && params.top+i < len(params.matches); i++ {
+		index := params.top + i
+		match := params.matches[index]
+
+		style := boxStyle
+		if index == params.current {
+			style = style.Background(tcell.ColorGray)
+		}
+
+		// the matched positions are bytes of the path, a char is highlighted when one of its bytes is matched
+		matched := 0
+		col := 0
+		for start, c := range match.path {
+			if col >= w-2 {
+				break
+			}
+
+			_, size := utf8.DecodeRuneInString(match.path[start:])
+
+			cellStyle := style
+			for matched < len(match.positions) && match.positions[matched] < start+size {
+				cellStyle = style.Foreground(tcell.ColorYellow).Bold(true)
+				matched++
+			}
+
+			e.screen.SetContent(x+1+col, y+2+i, c, nil, cellStyle)
+			col++
+		}
+	}
+
+	e.renderClippedTextOnStyle(y+h-1, x+1, w-2, e.finderStatus(), boxStyle.Foreground(tcell.ColorGray))
+	e.screen.ShowCursor(x+1+len(e.input.req)+len(e.input.buffers[INPUT_TEXT]), y)
+}
+
+// render the list of the opened buffers in the box of the finder, over the current buffer
+func (e *Editor) renderBufferList() {
+	x, y, w, h := e.getFinderBox()
+	if w <= 2 || h <= 2 {
+		return
+	}
+
+	boxStyle := e.renderBox(x, y, w, h)
+	e.renderClippedTextOnStyle(y, x+1, w-2, fmt.Sprintf("buffers (%d)", len(e.documents)), boxStyle.Bold(true))
+
+	e.bufferListParams.current = min(e.bufferListParams.current, len(e.documents)-1)
+
+	rows := h - 2
+	top := max(e.bufferListParams.current-rows+1, 0)
+
+	for i := 0; i < rows && top+i < len(e.documents); i++ {
+		index := top + i
+
+		style := boxStyle
+		if index == e.bufferListParams.current {
+			style = style.Background(tcell.ColorGray)
+		}
+
+		e.renderClippedTextOnStyle(y+2+i, x+1, w-2, e.documents[index].bufferListText(), style)
+	}
+
+	e.screen.HideCursor()
+}
+
 func (e *Editor) renderEditorTextOnScreen() {
 	e.renderInfo()
 
+	if e.mode == FINDER_MODE {
+		e.renderFinderBackground()
+		e.renderFinder()
+		return
+	}
+
+	if e.mode == BUFFER_LIST_MODE {
+		e.renderTextBackground()
+		e.renderBufferList()
+		return
+	}
+
 	if e.mode == NAVIGATION_MODE {
 		e.renderNavigation()
 		return
 	}
 
+	if e.mode == GREP_MODE {
+		e.renderGrepResults()
+		return
+	}
+
+	if e.mode == PROJECT_REPLACE_MODE {
+		e.renderProjectReplacePreview()
+		return
+	}
+
+	if e.sidebar.visible {
+		e.renderSidebar()
+	}
+
+	e.renderTabs()
+	e.renderWindows()
 	e.renderContent()
 	e.renderCursor()
 }
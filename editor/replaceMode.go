@@ -0,0 +1,213 @@
+package editor
+
+import "github.com/gdamore/tcell/v2"
+
+// check if the user is typing the replacement text in the replace mode
+func (editor *Editor) isTypingReplacement() bool {
+	return editor.searchParams.whichMode == REPLACE && editor.getInputCurrentBuffer() == NEW_TEXT
+}
+
+// remove the overlapping locations (a match starting inside the previous one) so each one can be replaced
+func nonOverlappingLocations(locations []Location, length int) []Location {
+	var result []Location
+
+	for _, loc := range locations {
+		if len(result) != 0 {
+			prev := result[len(result)-1]
+			if prev.getLine() == loc.getLine() && loc.getCol() < prev.getCol()+length {
+				continue
+			}
+		}
+
+		result = append(result, loc)
+	}
+
+	return result
+}
+
+// the replacements of a confirmation are undone together
+func (editor *Editor) saveReplaceUndoStep() {
+	if editor.searchParams.replaced == 0 {
+		editor.saveUndoStep(UNDO_CHANGE)
+	}
+}
+
+// replace the match at the index 'index' of the search locations and shift the locations that follow it on the same line
+func (editor *Editor) replaceSearchLocation(index int) {
+	oldText := editor.input.buffers[INPUT_TEXT]
+	newText := editor.input.buffers[NEW_TEXT]
+	delta := len(newText) - len(oldText)
+
+	editor.saveReplaceUndoStep()
+
+	loc := editor.searchParams.locations[index]
+	editor.buffer.findAndReplace(newText, oldText, &loc)
+	editor.searchParams.replaced++
+
+	replacedAt := editor.searchParams.locations[index]
+	for i := index + 1; i < len(editor.searchParams.locations); i++ {
+		next := &editor.searchParams.locations[i]
+		if next.getLine() == replacedAt.getLine() && next.getCol() > replacedAt.getCol() {
+			next.setCol(next.getCol() + delta)
+		}
+	}
+
+	// keep the end of the selected range on the same char
+	end := &editor.searchParams.end
+	if editor.searchParams.inSelection && end.getLine() == replacedAt.getLine() && replacedAt.getCol() < end.getCol() {
+		end.setCol(end.getCol() + delta)
+	}
+}
+
+// set the cursor at the end of the currently focused match
+func (editor *Editor) setCursorOnCurrentMatch() {
+	editor.gotoMatchEnd(editor.searchParams.locations[editor.searchParams.current], editor.input.buffers[INPUT_TEXT])
+}
+
+// ask for a confirmation before replacing all the matches
+func (editor *Editor) askReplaceAll() {
+	if !editor.canEdit() {
+		return
+	}
+
+	count := len(nonOverlappingLocations(editor.searchParams.locations, len(editor.input.buffers[INPUT_TEXT])))
+	if count == 0 {
+		editor.setMessage("no match to replace")
+		return
+	}
+
+	editor.searchParams.confirm = CONFIRM_REPLACE_ALL
+	editor.searchParams.replaced = 0
+	editor.setMessage("replace %d occurrences? (y/n)", count)
+}
+
+// replace all the matches
+func (editor *Editor) replaceAll() {
+	editor.searchParams.locations = nonOverlappingLocations(editor.searchParams.locations, len(editor.input.buffers[INPUT_TEXT]))
+	editor.replaceRemaining(0)
+}
+
+// replace all the matches starting from the index 'from'
+func (editor *Editor) replaceRemaining(from int) {
+	if from >= len(editor.searchParams.locations) {
+		return
+	}
+
+	locations := editor.searchParams.locations[from:]
+	editor.saveReplaceUndoStep()
+	editor.replaceLocations(locations, editor.input.buffers[INPUT_TEXT], editor.input.buffers[NEW_TEXT])
+	editor.searchParams.replaced += len(locations)
+
+	editor.gotoMatchEnd(locations[0], editor.input.buffers[NEW_TEXT])
+}
+
+// start asking for a confirmation on every match
+func (editor *Editor) startReplaceEach() {
+	if !editor.canEdit() {
+		return
+	}
+
+	editor.searchParams.locations = nonOverlappingLocations(editor.searchParams.locations, len(editor.input.buffers[INPUT_TEXT]))
+	if len(editor.searchParams.locations) == 0 {
+		editor.setMessage("no match to replace")
+		return
+	}
+
+	editor.searchParams.confirm = CONFIRM_REPLACE_EACH
+	editor.searchParams.current = 0
+	editor.searchParams.replaced = 0
+	editor.setCursorOnCurrentMatch()
+	editor.askReplaceCurrent()
+}
+
+func (editor *Editor) askReplaceCurrent() {
+	editor.setMessage("replace this match? (y/n/a/q)")
+}
+
+// move to the next match to confirm, or finish when there is none
+func (editor *Editor) nextMatchToConfirm() {
+	editor.searchParams.current++
+	if editor.searchParams.current >= len(editor.searchParams.locations) {
+		editor.finishReplace()
+		return
+	}
+
+	editor.setCursorOnCurrentMatch()
+	editor.askReplaceCurrent()
+}
+
+// get back to the normal mode and report how many matches were replaced
+func (editor *Editor) finishReplace() {
+	replaced := editor.searchParams.replaced
+	cursor := editor.realCursor
+
+	editor.switchToNormalFromSearchMode()
+	editor.realCursor = cursor
+	editor.setMessage("replaced %d occurrences", replaced)
+}
+
+// cancel the confirmation and get back to typing the replacement
+func (editor *Editor) cancelConfirm() {
+	editor.searchParams.confirm = CONFIRM_NONE
+	editor.searchAndSetCursor()
+}
+
+func (editor *Editor) handleReplaceAllAnswer(c rune) {
+	switch c {
+	case 'y', 'Y':
+		editor.replaceAll()
+		editor.finishReplace()
+	case 'n', 'N':
+		editor.cancelConfirm()
+	default:
+		editor.askReplaceAll()
+	}
+}
+
+func (editor *Editor) handleReplaceEachAnswer(c rune) {
+	switch c {
+	case 'y', 'Y':
+		editor.replaceSearchLocation(editor.searchParams.current)
+		editor.nextMatchToConfirm()
+	case 'n', 'N':
+		editor.nextMatchToConfirm()
+	case 'a', 'A':
+		editor.replaceRemaining(editor.searchParams.current)
+		editor.finishReplace()
+	case 'q', 'Q':
+		editor.finishReplace()
+	default:
+		editor.askReplaceCurrent()
+	}
+}
+
+// handle the answers of the replace confirmations
+func (editor *Editor) handleConfirmEventInSearchMode(ev tcell.Event) error {
+	evKey, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return nil
+	}
+
+	if evKey.Key() == tcell.KeyEscape {
+		if editor.searchParams.confirm == CONFIRM_REPLACE_EACH {
+			editor.finishReplace()
+			return nil
+		}
+
+		editor.cancelConfirm()
+		return nil
+	}
+
+	if evKey.Key() != tcell.KeyRune {
+		return nil
+	}
+
+	switch editor.searchParams.confirm {
+	case CONFIRM_REPLACE_ALL:
+		editor.handleReplaceAllAnswer(evKey.Rune())
+	case CONFIRM_REPLACE_EACH:
+		editor.handleReplaceEachAnswer(evKey.Rune())
+	}
+
+	return nil
+}
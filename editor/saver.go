@@ -3,57 +3,134 @@ package editor
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
-// save the content of the editor buffer to a file
-// main function
-func (editor *Editor) saveContent(f *os.File) error {
-	for _, line := range editor.buffer.lines {
-		_, err := f.Write([]byte(line.content))
-		if err != nil {
-			return err
-		}
-		_, err = f.Write([]byte("\n"))
-		if err != nil {
-			return err
+// get the content of the buffer as it is written to a file: its lines joined by '\n', nothing after the last one,
+// so a file loaded then saved is unchanged (the final new line of the file is the empty last line of the buffer)
+func (buffer *Buffer) saveContent() []byte {
+	var content []byte
+
+	for i, line := range buffer.lines {
+		if i != 0 {
+			content = append(content, '\n')
 		}
+		content = append(content, line.content...)
 	}
 
-	return nil
+	return content
 }
 
-func (editor *Editor) checkFileInfoAndGetFile(fileInfo os.FileInfo) (*os.File, error) {
-	if fileInfo.IsDir() {
-		return nil, fmt.Errorf("can not handle directories right now")
+// get the permissions of the file at 'path' (the default ones if it does not exist yet)
+func getFilePerm(path string) (os.FileMode, error) {
+	fileInfo, err := os.Stat(path)
+	if err == nil {
+		if fileInfo.IsDir() {
+			return 0, fmt.Errorf("can not handle directories right now")
+		}
+
+		return fileInfo.Mode().Perm(), nil
+	}
+
+	if os.IsNotExist(err) {
+		return 0644, nil
 	}
 
-	return os.OpenFile(editor.config.CurrentFile, os.O_WRONLY|os.O_TRUNC, fileInfo.Mode().Perm())
+	return 0, err
 }
 
-func (editor *Editor) getInputFile() (*os.File, error) {
-	fileInfo, err := os.Stat(editor.config.CurrentFile)
+// write the content into a temporary file next to 'path' then rename it to 'path',
+// so the file is either the old one or the new one, never a half written one
+func writeFileAtomically(path string, content []byte) error {
+	// replace the target of a symbolic link, not the link itself
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+
+	perm, err := getFilePerm(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := f.Name()
+
+	_, err = f.Write(content)
 	if err == nil {
-		return editor.checkFileInfoAndGetFile(fileInfo)
+		err = f.Sync()
 	}
 
-	if os.IsNotExist(err) {
-		return os.OpenFile(editor.config.CurrentFile, os.O_CREATE|os.O_WRONLY, 0644)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
 	}
 
-	panic("unhandled situation")
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
 }
 
-// save into a hardcoded filepath
-func (editor *Editor) save() error {
-	f, err := editor.getInputFile()
+// save the content of the buffer of the document into its file, unless another program changed the file
+func (doc *Document) save() error {
+	_, changed, err := doc.getDiskChange()
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		return fmt.Errorf("%s: %w", doc.getName(), errFileChanged)
+	}
+
+	return doc.overwrite()
+}
+
+// save the content of the buffer of the document into its file, even if another program changed the file
+func (doc *Document) overwrite() error {
+	content := doc.buffer.saveContent()
+
+	err := writeFileAtomically(doc.path, content)
 	if err != nil {
 		return err
 	}
 
-	err = editor.saveContent(f)
+	state, err := getFileState(doc.path, content)
 	if err != nil {
 		return err
 	}
 
-	return f.Close()
+	doc.diskState = state
+	doc.ignoredDiskState = FileState{}
+	doc.buffer.modified = false
+	doc.markUndoStepsModified()
+	return nil
+}
+
+// save the document into its file, every save of the editor goes through it so none is made when the files can not be changed
+func (editor *Editor) saveDocument(doc *Document) error {
+	err := editor.getFilesChangeError()
+	if err != nil {
+		return err
+	}
+
+	return doc.save()
+}
+
+// save the content of the editor buffer into the current file
+func (editor *Editor) save() error {
+	return editor.saveDocument(editor.getCurrentDocument())
 }
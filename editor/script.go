@@ -0,0 +1,228 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const SCRIPT_COMMENT_PREFIX = "#"
+
+// a command of the scripts, its arguments are words or Go quoted strings ("a \"b\"\n")
+type ScriptCommand struct {
+	usage   string
+	minArgs int
+	maxArgs int
+	edits   bool // the command changes the buffer or the file
+	run     func(editor *Editor, args []string) error
+}
+
+var scriptCommands = map[string]ScriptCommand{
+	"goto": {
+		usage:   "goto LINE [COLUMN]",
+		minArgs: 1,
+		maxArgs: 2,
+		run: func(editor *Editor, args []string) error {
+			var numbers []int
+			for _, arg := range args {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid number %q, the lines and the columns start at 1", arg)
+				}
+				numbers = append(numbers, n-1)
+			}
+
+			if len(numbers) == 1 {
+				numbers = append(numbers, 0)
+			}
+
+			return editor.gotoLocation(numbers[0], numbers[1])
+		},
+	},
+	"search": {
+		usage:   "search TEXT",
+		minArgs: 1,
+		maxArgs: 1,
+		run: func(editor *Editor, args []string) error {
+			return editor.searchForward(args[0])
+		},
+	},
+	"replace": {
+		usage:   "replace TEXT NEW_TEXT",
+		minArgs: 2,
+		maxArgs: 2,
+		edits:   true,
+		run: func(editor *Editor, args []string) error {
+			if editor.replaceAllText(args[0], args[1]) == 0 {
+				return fmt.Errorf("%q not found", args[0])
+			}
+			return nil
+		},
+	},
+	"insert": {
+		usage:   "insert TEXT",
+		minArgs: 1,
+		maxArgs: 1,
+		edits:   true,
+		run: func(editor *Editor, args []string) error {
+			return editor.insertText(args[0])
+		},
+	},
+	"delete-line": {
+		usage: "delete-line",
+		edits: true,
+		run: func(editor *Editor, args []string) error {
+			return editor.deleteLine()
+		},
+	},
+	"save": {
+		usage: "save",
+		edits: true,
+		run: func(editor *Editor, args []string) error {
+			return editor.saveCurrentDocument()
+		},
+	},
+}
+
+type ScriptLine struct {
+	number  int // line of the script, from 1
+	name    string
+	command ScriptCommand
+	args    []string
+}
+
+// split a line of a script into its words, the quoted ones are unquoted
+func splitScriptLine(line string) ([]string, error) {
+	var words []string
+
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return words, nil
+		}
+
+		if line[0] != '"' {
+			end := strings.IndexAny(line, " \t")
+			if end == -1 {
+				end = len(line)
+			}
+
+			words = append(words, line[:end])
+			line = line[end:]
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", line)
+		}
+
+		word, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+
+		words = append(words, word)
+		line = line[len(quoted):]
+	}
+}
+
+// read the commands of a script, one by line (the empty lines and the comments are skipped)
+func parseScript(content string) ([]ScriptLine, error) {
+	var script []ScriptLine
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, SCRIPT_COMMENT_PREFIX) {
+			continue
+		}
+
+		words, err := splitScriptLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", i+1, err)
+		}
+
+		command, ok := scriptCommands[words[0]]
+		if !ok {
+			return nil, fmt.Errorf("%d: unknown command %q", i+1, words[0])
+		}
+
+		args := words[1:]
+		if len(args) < command.minArgs || len(args) > command.maxArgs {
+			return nil, fmt.Errorf("%d: usage: %s", i+1, command.usage)
+		}
+
+		script = append(script, ScriptLine{number: i + 1, name: words[0], command: command, args: args})
+	}
+
+	return script, nil
+}
+
+// open the file at 'path' in an editor without a screen, a file that does not exist is created when it is saved
+func newScriptEditor(editorConfig EditorConfiguration, path string) (*Editor, error) {
+	editor, err := newEditor(editorConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	doc, err := loadDocument(path)
+	if os.IsNotExist(err) {
+		doc, err = newDocument(path), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	editor.addDocument(doc)
+	return editor, nil
+}
+
+// run the commands of the script at 'scriptPath' on each file without a terminal,
+// it stops at the first command that fails (the changes not saved yet are lost)
+func RunScript(editorConfig EditorConfiguration, scriptPath string, files []string) error {
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return err
+	}
+
+	script, err := parseScript(string(content))
+	if err != nil {
+		return fmt.Errorf("%s:%w", scriptPath, err)
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no file to run the script on")
+	}
+
+	for _, path := range files {
+		editor, err := newScriptEditor(editorConfig, path)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range script {
+			err = editor.runScriptLine(line)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %s: %w", scriptPath, line.number, path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (editor *Editor) runScriptLine(line ScriptLine) error {
+	doc := editor.getCurrentDocument()
+	if line.command.edits && editor.isReadOnly(doc) {
+		return fmt.Errorf("%s: %s is read-only", line.name, doc.getName())
+	}
+
+	return line.command.run(editor, line.args)
+}
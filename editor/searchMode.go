@@ -8,6 +8,37 @@ func (editor *Editor) setSearchMode() {
 	editor.enableInputBuffer()
 	editor.setInputCurrentBuffer(INPUT_TEXT)
 	editor.setInputBufferInputRequestString("find text: ")
+
+	for i := range editor.histories {
+		editor.histories[i].reset()
+	}
+}
+
+// enter the search mode with the search field filled with 'text'
+func (editor *Editor) setSearchModeWithText(whichMode int, text string) {
+	editor.setSearchMode()
+	editor.setSearchSubMode(whichMode)
+	editor.prefillInputBuffer(text)
+	editor.focusMatchAtCursor()
+}
+
+// search the text of the input buffer and focus the first match that ends after the cursor, without jumping to the top
+func (editor *Editor) focusMatchAtCursor() {
+	editor.updateSearchLocations(editor.input.buffers[INPUT_TEXT])
+	if len(editor.searchParams.locations) == 0 {
+		return
+	}
+
+	editor.searchParams.current = 0
+	for i, loc := range editor.searchParams.locations {
+		matchEnd := newLocation(loc.getLine(), loc.getCol()+len(editor.input.buffers[INPUT_TEXT]))
+		if !matchEnd.isBefore(editor.realCursor) {
+			editor.searchParams.current = i
+			break
+		}
+	}
+
+	editor.setCursorOnCurrentMatch()
 }
 
 func (editor *Editor) setSearchSubMode(whichMode int) {
@@ -33,8 +64,7 @@ func (editor *Editor) searchAndSetCursor() {
 	}
 
 	// set the real cursor
-	row, col := editor.searchParams.locations[editor.searchParams.current].get()
-	editor.realCursor = newLocation(row, col+len(editor.input.buffers[INPUT_TEXT]))
+	editor.gotoMatchEnd(editor.searchParams.locations[editor.searchParams.current], editor.input.buffers[INPUT_TEXT])
 }
 
 // get the next position of the cursor from the current matching word (search function)
@@ -49,8 +79,7 @@ func (editor *Editor) updateSearchPointer() {
 	editor.searchParams.current %= locationsLen
 
 	// updating the real cursor
-	row, col := editor.searchParams.locations[editor.searchParams.current].get()
-	editor.realCursor = newLocation(row, col+len(editor.input.buffers[INPUT_TEXT]))
+	editor.gotoMatchEnd(editor.searchParams.locations[editor.searchParams.current], editor.input.buffers[INPUT_TEXT])
 }
 
 // lookup a location in all the locations of the matching positions (after the search)
@@ -66,21 +95,41 @@ func (editor *Editor) lookupLocationInSearchLocations(loc Location) bool {
 
 // search a text in the editor buffer and set all the locations where found
 func (editor *Editor) updateSearchLocations(text string) {
+	if editor.searchParams.inSelection {
+		editor.searchParams.locations = editor.buffer.searchInRange(text, editor.searchParams.start, editor.searchParams.end)
+		return
+	}
+
 	editor.searchParams.locations = editor.buffer.search(text)
 }
 
+// enter the search mode with the search restricted to the range [start, end)
+func (editor *Editor) setSearchModeInRange(whichMode int, start, end Location) {
+	editor.setSearchMode()
+	editor.setSearchSubMode(whichMode)
+	editor.searchParams.inSelection = true
+	editor.searchParams.start = start
+	editor.searchParams.end = end
+}
+
 func (e *Editor) replaceOnCursor() {
-	newText := e.input.buffers[NEW_TEXT]
-	oldText := e.input.buffers[INPUT_TEXT]
-	e.realCursor.setCol(e.realCursor.getCol() - len(oldText))
+	if !e.canEdit() {
+		return
+	}
+
+	index := e.searchParams.current
+	if index >= len(e.searchParams.locations) {
+		return
+	}
 
-	e.buffer.findAndReplace(newText, oldText, &e.realCursor)
+	// every replace on the cursor is undone on its own, the end of the selected range follows it
+	loc := e.searchParams.locations[index]
+	e.searchParams.replaced = 0
+	e.replaceSearchLocation(index)
 	e.searchParams.hasReplaced = true
 
-	currentLocation := e.realCursor
 	e.searchAndSetCursor()
-
-	e.realCursor = currentLocation
+	e.gotoMatchEnd(loc, e.input.buffers[NEW_TEXT])
 }
 
 func (editor *Editor) handleEscapeKeyInSearchMode() {
@@ -103,11 +152,13 @@ func (editor *Editor) handleEnterKeyInSearchMode() {
 	mode := editor.searchParams.whichMode
 
 	if mode == SEARCH {
+		editor.addToHistory(INPUT_TEXT)
 		editor.updateSearchPointer()
 		return
 	}
 
 	if editor.getInputCurrentBuffer() == INPUT_TEXT {
+		editor.addToHistory(INPUT_TEXT)
 		editor.setInputCurrentBuffer(NEW_TEXT)
 		editor.setInputBufferInputRequestString("replace with: ")
 		return
@@ -119,6 +170,7 @@ func (editor *Editor) handleEnterKeyInSearchMode() {
 	}
 
 	if !editor.searchParams.hasReplaced {
+		editor.addToHistory(NEW_TEXT)
 		editor.replaceOnCursor()
 		return
 	}
@@ -129,11 +181,21 @@ func (editor *Editor) handleEnterKeyInSearchMode() {
 
 // handle search mode commands
 func (editor *Editor) handleSearchModeEvent(ev tcell.Event) error {
+	if editor.searchParams.confirm != CONFIRM_NONE {
+		return editor.handleConfirmEventInSearchMode(ev)
+	}
+
 	shouldMakeSearch := false
 
 	switch ev := ev.(type) {
 	case *tcell.EventKey:
 		switch {
+		case ev.Key() == tcell.KeyCtrlA && editor.isTypingReplacement():
+			editor.addToHistory(NEW_TEXT)
+			editor.askReplaceAll()
+		case ev.Key() == tcell.KeyCtrlY && editor.isTypingReplacement():
+			editor.addToHistory(NEW_TEXT)
+			editor.startReplaceEach()
 		case ev.Key() == tcell.KeyEscape:
 			editor.handleEscapeKeyInSearchMode()
 		case ev.Key() == tcell.KeyBackspace2:
@@ -141,6 +203,10 @@ func (editor *Editor) handleSearchModeEvent(ev tcell.Event) error {
 			shouldMakeSearch = true
 		case ev.Key() == tcell.KeyEnter:
 			editor.handleEnterKeyInSearchMode()
+		case ev.Key() == tcell.KeyUp:
+			shouldMakeSearch = editor.showPrevHistoryEntry()
+		case ev.Key() == tcell.KeyDown:
+			shouldMakeSearch = editor.showNextHistoryEntry()
 		case ev.Key() == tcell.KeyRune:
 			editor.insertCharToInputBuffer(ev.Rune())
 			shouldMakeSearch = true
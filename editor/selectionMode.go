@@ -88,6 +88,12 @@ func (e *Editor) moveCursorRightInSelectionMode() {
 }
 
 func (e *Editor) removeContentInSelectionMode() error {
+	if !e.canEdit() {
+		return nil
+	}
+
+	e.saveUndoStep(UNDO_CHANGE)
+
 	_, end := sortLocations(e.selParams.startLocation, e.selParams.endLocation)
 	err := e.buffer.removeString(e.countDistanceBetweenSelectionModeBounds(), &end)
 	if err != nil {
@@ -103,6 +109,25 @@ func (e *Editor) switchToInsertFromSelectionMode() {
 	e.mode = INSERT_MODE
 }
 
+// search (or replace) only inside the selected text, the field is filled with the selected text when it fits
+// in one line; with nothing selected the whole buffer is searched for the word of the cursor
+func (e *Editor) searchInSelection(whichMode int) {
+	start, end := sortLocations(e.selParams.startLocation, e.selParams.endLocation)
+	e.selParams = EditorSelectionModeParams{}
+
+	if start == end {
+		e.setSearchModeWithText(whichMode, e.buffer.wordAt(e.realCursor))
+		return
+	}
+
+	e.setSearchModeInRange(whichMode, start, end)
+	if start.getLine() == end.getLine() {
+		e.realCursor = start
+		e.prefillInputBuffer(e.buffer.getText(start, end))
+		e.focusMatchAtCursor()
+	}
+}
+
 func (e *Editor) skipLeftTokenInSelectionMode() {
 	e.skipLeftToken()
 	e.selParams.endLocation = e.realCursor
@@ -115,7 +140,18 @@ func (e *Editor) skipRightTokenInSelectionMode() {
 
 func (e *Editor) handleSelectionModeEvent(ev tcell.Event) error {
 	switch ev := ev.(type) {
+	case *tcell.EventMouse:
+		e.handleTextMouseEvent(ev)
 	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyCtrlF:
+			e.searchInSelection(SEARCH)
+			return nil
+		case tcell.KeyCtrlR:
+			e.searchInSelection(REPLACE)
+			return nil
+		}
+
 		if ev.Modifiers()&tcell.ModShift == 0 {
 			switch ev.Key() {
 			case tcell.KeyRune:
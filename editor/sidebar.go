@@ -0,0 +1,346 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	SIDEBAR_WIDTH = 30
+
+	SIDEBAR_EXPANDED_MARKER  = "▾ "
+	SIDEBAR_COLLAPSED_MARKER = "▸ "
+	SIDEBAR_FILE_MARKER      = "  "
+	SIDEBAR_INDENT           = "  "
+)
+
+// a file or a directory of the tree, the children of a directory are read the first time it is expanded
+type TreeNode struct {
+	name     string
+	path     string
+	isDir    bool
+	expanded bool
+	loaded   bool
+	parent   *TreeNode
+	children []*TreeNode
+}
+
+type EditorSidebar struct {
+	visible bool
+	focused bool
+	root    *TreeNode
+	current int // index of the selected node in the visible nodes
+	top     int // index of the first rendered node
+}
+
+func newTreeNode(path string, isDir bool, parent *TreeNode) *TreeNode {
+	return &TreeNode{
+		name:   filepath.Base(path),
+		path:   path,
+		isDir:  isDir,
+		parent: parent,
+	}
+}
+
+// read the children of the directory (only once)
+func (node *TreeNode) load() error {
+	if node.loaded || !node.isDir {
+		return nil
+	}
+
+	entries, err := os.ReadDir(node.path)
+	if err != nil {
+		return err
+	}
+
+	node.children = nil
+	for _, entry := range entries {
+		node.children = append(node.children, newTreeNode(filepath.Join(node.path, entry.Name()), entry.IsDir(), node))
+	}
+
+	node.loaded = true
+	return nil
+}
+
+// read the children of the loaded directory again, the nodes of the entries still there are kept (with their children)
+func (node *TreeNode) reload() error {
+	if !node.loaded {
+		return nil
+	}
+
+	entries, err := os.ReadDir(node.path)
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]*TreeNode, len(node.children))
+	for _, child := range node.children {
+		previous[child.name] = child
+	}
+
+	node.children = nil
+	for _, entry := range entries {
+		child, ok := previous[entry.Name()]
+		if !ok || child.isDir != entry.IsDir() {
+			child = newTreeNode(filepath.Join(node.path, entry.Name()), entry.IsDir(), node)
+		}
+		node.children = append(node.children, child)
+	}
+
+	return nil
+}
+
+// find the node of the file at 'path' among the loaded ones, nil when there is none
+func (node *TreeNode) find(path string) *TreeNode {
+	if node.path == path {
+		return node
+	}
+
+	for _, child := range node.children {
+		if child.isDir && (child.path == path || strings.HasPrefix(path, child.path+string(filepath.Separator))) {
+			return child.find(path)
+		}
+	}
+
+	return nil
+}
+
+// get the depth of the node in the tree (the root is at 0)
+func (node *TreeNode) depth() int {
+	depth := 0
+	for parent := node.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+
+	return depth
+}
+
+// get the nodes shown in the sidebar: the children of the expanded directories, in order
+func (node *TreeNode) visibleNodes() []*TreeNode {
+	var nodes []*TreeNode
+
+	for _, child := range node.children {
+		nodes = append(nodes, child)
+		if child.expanded {
+			nodes = append(nodes, child.visibleNodes()...)
+		}
+	}
+
+	return nodes
+}
+
+// show the sidebar (rooted at the navigation directory) and focus it, or hide it
+func (e *Editor) toggleSidebar() {
+	if e.sidebar.visible {
+		e.sidebar.visible = false
+		e.sidebar.focused = false
+		return
+	}
+
+	if e.sidebar.root == nil {
+		dir, err := filepath.Abs(e.getNavigationStartDir())
+		if err != nil {
+			e.setMessage("%s", err)
+			return
+		}
+
+		root := newTreeNode(dir, true, nil)
+		root.expanded = true
+		err = root.load()
+		if err != nil {
+			e.setMessage("%s", err)
+			return
+		}
+
+		e.sidebar.root = root
+	}
+
+	e.sidebar.visible = true
+	e.sidebar.focused = true
+}
+
+// read again the directory 'dir' in the sidebar once its entries changed, the selected node stays selected
+func (e *Editor) refreshSidebarDir(dir string) {
+	if e.sidebar.root == nil {
+		return
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+
+	node := e.sidebar.root.find(dir)
+	if node == nil {
+		return
+	}
+
+	selected := e.getSelectedTreeNode()
+
+	err = node.reload()
+	if err != nil {
+		e.setMessage("%s", err)
+		return
+	}
+
+	e.selectTreeNode(selected)
+}
+
+// move the focus between the sidebar and the buffer
+func (e *Editor) switchSidebarFocus() {
+	if !e.sidebar.visible {
+		return
+	}
+
+	e.sidebar.focused = !e.sidebar.focused
+}
+
+// get the first column of the text area (the sidebar is on its left)
+func (e *Editor) getTextAreaLeft() int {
+	if e.sidebar.visible {
+		return SIDEBAR_WIDTH + 1
+	}
+
+	return 0
+}
+
+func (e *Editor) getSelectedTreeNode() *TreeNode {
+	nodes := e.sidebar.root.visibleNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	if e.sidebar.current >= len(nodes) {
+		e.sidebar.current = len(nodes) - 1
+	}
+
+	return nodes[e.sidebar.current]
+}
+
+func (e *Editor) updateSidebarIndexCursorDown() {
+	if e.sidebar.current < len(e.sidebar.root.visibleNodes())-1 {
+		e.sidebar.current++
+	}
+}
+
+func (e *Editor) updateSidebarIndexCursorUp() {
+	if e.sidebar.current > 0 {
+		e.sidebar.current--
+	}
+}
+
+// select the node 'node' if it is visible
+func (e *Editor) selectTreeNode(node *TreeNode) {
+	for i, visible := range e.sidebar.root.visibleNodes() {
+		if visible == node {
+			e.sidebar.current = i
+			return
+		}
+	}
+}
+
+func (e *Editor) expandSelectedTreeNode() {
+	node := e.getSelectedTreeNode()
+	if node == nil || !node.isDir {
+		return
+	}
+
+	err := node.load()
+	if err != nil {
+		e.setMessage("%s", err)
+		return
+	}
+
+	node.expanded = true
+}
+
+// collapse the selected directory, or select the parent directory of the selected node
+func (e *Editor) collapseSelectedTreeNode() {
+	node := e.getSelectedTreeNode()
+	if node == nil {
+		return
+	}
+
+	if node.isDir && node.expanded {
+		node.expanded = false
+		return
+	}
+
+	if node.parent != e.sidebar.root {
+		node.parent.expanded = false
+		e.selectTreeNode(node.parent)
+	}
+}
+
+// open the selected file in the buffer, or expand (collapse) the selected directory
+func (e *Editor) handleEnterKeyInSidebar() error {
+	node := e.getSelectedTreeNode()
+	if node == nil {
+		return nil
+	}
+
+	if node.isDir {
+		if node.expanded {
+			node.expanded = false
+			return nil
+		}
+
+		e.expandSelectedTreeNode()
+		return nil
+	}
+
+	err := e.openFile(node.path)
+	if err != nil {
+		e.setMessage("%s", err)
+		return nil
+	}
+
+	e.sidebar.focused = false
+	return nil
+}
+
+// handle the keys while the sidebar has the focus
+func (e *Editor) handleSidebarEvent(ev tcell.Event) error {
+	evKey, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return nil
+	}
+
+	switch evKey.Key() {
+	case tcell.KeyCtrlB:
+		e.toggleSidebar()
+	case tcell.KeyCtrlE, tcell.KeyEscape:
+		e.switchSidebarFocus()
+	case tcell.KeyDown:
+		e.updateSidebarIndexCursorDown()
+	case tcell.KeyUp:
+		e.updateSidebarIndexCursorUp()
+	case tcell.KeyRight:
+		e.expandSelectedTreeNode()
+	case tcell.KeyLeft:
+		e.collapseSelectedTreeNode()
+	case tcell.KeyEnter:
+		return e.handleEnterKeyInSidebar()
+	}
+
+	return nil
+}
+
+// get the text of a node in the sidebar: indentation, marker then name
+func (node *TreeNode) sidebarText() string {
+	marker := SIDEBAR_FILE_MARKER
+	name := node.name
+
+	if node.isDir {
+		marker = SIDEBAR_COLLAPSED_MARKER
+		if node.expanded {
+			marker = SIDEBAR_EXPANDED_MARKER
+		}
+		name += "/"
+	}
+
+	return strings.Repeat(SIDEBAR_INDENT, node.depth()-1) + marker + name
+}
@@ -0,0 +1,69 @@
+package editor
+
+import (
+	"io"
+	"os"
+)
+
+const STDIN_DOCUMENT_NAME = "[stdin]"
+
+// read the content of the standard input into a new document, it has no file until it is saved
+func loadStdinDocument(stdin io.Reader) (*Document, error) {
+	content, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newDocument("")
+	doc.name = STDIN_DOCUMENT_NAME
+	err = doc.buffer.load(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// the content is new to the editor, it is lost if it is not saved (or written to the standard output)
+	doc.buffer.modified = !doc.buffer.isEmpty()
+	return doc, nil
+}
+
+// open the content of the standard input, the keys are still read from the terminal
+func (editor *Editor) loadStdin() error {
+	doc, err := loadStdinDocument(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	// it can not be saved, quitting must not ask to
+	if editor.config.ReadOnly {
+		doc.buffer.modified = false
+	}
+
+	editor.addDocument(doc)
+	editor.output = doc
+	return nil
+}
+
+// get the document written to the standard output on exit: the one read from the standard input,
+// or the current one when there is none
+func (editor *Editor) getOutputDocument() *Document {
+	if editor.output != nil && editor.findDocumentByPointer(editor.output) != -1 {
+		return editor.output
+	}
+
+	return editor.getCurrentDocument()
+}
+
+// check if the document does not need to be saved as its content goes to the standard output
+func (editor *Editor) isOutputDocument(doc *Document) bool {
+	return editor.config.WriteStdout && doc.path == "" && doc == editor.getOutputDocument()
+}
+
+// write the content of the output document, it must be called once the screen is closed
+func (editor *Editor) WriteOutput(stdout io.Writer) error {
+	if !editor.config.WriteStdout {
+		return nil
+	}
+
+	_, err := stdout.Write(editor.getOutputDocument().buffer.saveContent())
+	return err
+}
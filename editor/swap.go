@@ -0,0 +1,252 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	SWAP_FILE_SUFFIX    = ".geditor.swp"
+	SWAP_FILE_HEADER    = "geditor swap"
+	SWAP_WRITE_INTERVAL = 2 * time.Second
+	SWAP_QUEUE_SIZE     = 64
+)
+
+// event posted regularly to write the swap files of the modified documents
+type EventSwapTick struct {
+	tcell.EventTime
+}
+
+// a swap file to write (or to remove when there is no content)
+type swapWrite struct {
+	path    string
+	content []byte
+}
+
+type EditorSwap struct {
+	writes chan swapWrite // the swap files are written in this order by another goroutine
+	done   chan struct{}  // closed once all the swap files are written
+	cancel chan struct{}  // closed to stop posting the ticks
+}
+
+// get the path of the swap file of the file at 'path' ("dir/main.go" gives "dir/.main.go.geditor.swp")
+func getSwapPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+SWAP_FILE_SUFFIX)
+}
+
+func isSwapFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, SWAP_FILE_SUFFIX)
+}
+
+// get the content of a swap file: a header with the pid of the editor that wrote it, then the text
+func swapFileContent(text []byte) []byte {
+	header := fmt.Sprintf("%s %d\n", SWAP_FILE_HEADER, os.Getpid())
+	return append([]byte(header), text...)
+}
+
+// read the pid of the editor that wrote the swap file and the text saved in it
+func parseSwapFile(content []byte) (pid int, text []byte, err error) {
+	header, text, found := bytes.Cut(content, []byte("\n"))
+	if !found {
+		return 0, nil, fmt.Errorf("invalid swap file")
+	}
+
+	_, err = fmt.Sscanf(string(header), SWAP_FILE_HEADER+" %d", &pid)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid swap file header")
+	}
+
+	return pid, text, nil
+}
+
+// write the swap files in the background and post a tick regularly
+func (e *Editor) startSwap() {
+	writes := make(chan swapWrite, SWAP_QUEUE_SIZE)
+	done := make(chan struct{})
+	cancel := make(chan struct{})
+
+	e.swap = EditorSwap{writes: writes, done: done, cancel: cancel}
+
+	go func() {
+		// the swap files are a safety net, an error while writing one must not stop the editing
+		for write := range writes {
+			if write.content == nil {
+				os.Remove(write.path)
+				continue
+			}
+			writeFileAtomically(write.path, write.content)
+		}
+		close(done)
+	}()
+
+	screen := e.screen
+	go func() {
+		ticker := time.NewTicker(SWAP_WRITE_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-cancel:
+				return
+			case <-ticker.C:
+				ev := &EventSwapTick{}
+				ev.SetEventNow()
+				postEvent(screen, ev, cancel)
+			}
+		}
+	}()
+}
+
+// stop the ticks and wait until the swap files are written
+func (e *Editor) stopSwap() {
+	if e.swap.writes == nil {
+		return
+	}
+
+	close(e.swap.cancel)
+	close(e.swap.writes)
+	<-e.swap.done
+
+	e.swap = EditorSwap{}
+}
+
+func (e *Editor) queueSwapWrite(path string, content []byte) {
+	if e.swap.writes == nil {
+		return
+	}
+
+	e.swap.writes <- swapWrite{path: path, content: content}
+}
+
+// write the swap file of the document if its content changed since the last one, remove it once the document is saved
+func (e *Editor) updateSwapFile(doc *Document) {
+	if doc.path == "" {
+		return
+	}
+
+	if !doc.buffer.modified {
+		e.removeSwapFile(doc)
+		return
+	}
+
+	text := doc.buffer.saveContent()
+	if doc.swapText != nil && bytes.Equal(text, doc.swapText) {
+		return
+	}
+
+	doc.swapText = text
+	e.queueSwapWrite(getSwapPath(doc.path), swapFileContent(text))
+}
+
+// remove the swap file written for the document
+func (e *Editor) removeSwapFile(doc *Document) {
+	if doc.swapText == nil {
+		return
+	}
+
+	doc.swapText = nil
+	e.queueSwapWrite(getSwapPath(doc.path), nil)
+}
+
+func (e *Editor) updateSwapFiles() {
+	for _, doc := range e.documents {
+		e.updateSwapFile(doc)
+	}
+}
+
+func (e *Editor) removeSwapFiles() {
+	for _, doc := range e.documents {
+		e.removeSwapFile(doc)
+	}
+}
+
+// look for the swap file of a crashed editor next to the file of the document and ask what to do with it
+func (e *Editor) checkSwapFile(doc *Document) {
+	// a headless replay shows the file as it is, the swap file is left for the editor of the user
+	if e.config.Headless {
+		return
+	}
+
+	swapPath := getSwapPath(doc.path)
+
+	content, err := os.ReadFile(swapPath)
+	if err != nil {
+		return
+	}
+
+	pid, text, err := parseSwapFile(content)
+	if err != nil {
+		e.setMessage("%s: %s", swapPath, err)
+		return
+	}
+
+	if pid != os.Getpid() && processIsRunning(pid) {
+		e.setMessage("%s is also opened by another editor (pid %d)", doc.getName(), pid)
+		return
+	}
+
+	// nothing can be restored nor removed, the swap file is left for an editor that can
+	if e.config.ReadOnly {
+		e.setMessage("%s has unsaved changes from a crashed session, open it without --readonly to recover them", doc.getName())
+		return
+	}
+
+	// nothing was lost (the file was saved just before the crash)
+	if bytes.Equal(text, doc.buffer.saveContent()) {
+		os.Remove(swapPath)
+		return
+	}
+
+	e.askSwapRecovery(doc, text, nil)
+}
+
+// ask to restore the text found in the swap file of the document, 'diff' is the document showing the differences
+func (e *Editor) askSwapRecovery(doc *Document, text []byte, diff *Document) {
+	question := fmt.Sprintf("%s has unsaved changes from a crashed session (r: restore, d: diff, x: discard) ", doc.getName())
+	e.askQuestion(question, func(answer rune) error {
+		switch answer {
+		case 'd', 'D':
+			if diff == nil {
+				diff = newSwapDiffDocument(doc, text)
+				e.addDocument(diff)
+			}
+			e.askSwapRecovery(doc, text, diff)
+			return nil
+		case 'r', 'R':
+			err := doc.buffer.load(text)
+			if err != nil {
+				return err
+			}
+			doc.buffer.modified = true
+			doc.swapText = text
+		case 'x', 'X':
+			os.Remove(getSwapPath(doc.path))
+		}
+
+		// the swap file is kept when the question is cancelled
+		if diff != nil {
+			e.removeDocument(e.findDocumentByPointer(diff))
+		}
+
+		e.switchToDocument(e.findDocumentByPointer(doc))
+		return nil
+	})
+}
+
+// get a document showing the changes between the file of the document and the text of its swap file
+func newSwapDiffDocument(doc *Document, text []byte) *Document {
+	fileLines := strings.Split(string(doc.buffer.saveContent()), "\n")
+	swapLines := strings.Split(string(text), "\n")
+
+	diff := newDocument("")
+	diff.name = "[diff] " + doc.getName()
+	diff.buffer.load([]byte(strings.Join(diffText(diffLines(fileLines, swapLines)), "\n")))
+
+	return diff
+}
@@ -0,0 +1,222 @@
+package editor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	TABS_BAR_HEIGHT = 1
+
+	TABS_NAME_SEPARATOR  = " — "
+	TABS_MODIFIED_MARKER = " [+]"
+	TABS_OVERFLOW_MARKER = "…"
+)
+
+// a tab rendered in the tabs bar
+type Tab struct {
+	document int // index of the document of the tab
+	col      int // first column of the tab
+	label    string
+}
+
+// get the first row of the text area (the tabs bar is above it)
+func (e *Editor) getTextAreaTop() int {
+	return TABS_BAR_HEIGHT
+}
+
+// get the last 'count' directories of the directory of 'path' ("/src/cmd/a/main.go" with 2 gives "cmd/a")
+func parentDirs(path string, count int) string {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	if count > len(dirs) {
+		count = len(dirs)
+	}
+
+	return strings.Trim(strings.Join(dirs[len(dirs)-count:], "/"), "/")
+}
+
+// get the names of the tabs, the documents with the same file name get the shortest part of
+// their directory that tells them apart ("main.go — cmd/a" and "main.go — cmd/b")
+func (e *Editor) getTabNames() []string {
+	names := make([]string, len(e.documents))
+
+	sameName := map[string][]int{}
+	for i, doc := range e.documents {
+		names[i] = doc.getName()
+		if doc.path != "" {
+			sameName[names[i]] = append(sameName[names[i]], i)
+		}
+	}
+
+	for _, indexes := range sameName {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		depth := 1
+		for ; depth < 64; depth++ {
+			seen := map[string]bool{}
+			for _, i := range indexes {
+				seen[parentDirs(e.documents[i].path, depth)] = true
+			}
+
+			if len(seen) == len(indexes) {
+				break
+			}
+		}
+
+		for _, i := range indexes {
+			names[i] += TABS_NAME_SEPARATOR + parentDirs(e.documents[i].path, depth)
+		}
+	}
+
+	return names
+}
+
+// get the tabs that fit in the tabs bar, the tab of the current document is always in them
+func (e *Editor) getTabs() []Tab {
+	w, _ := e.screen.Size()
+	left := e.getTextAreaLeft()
+	width := w - left
+
+	names := e.getTabNames()
+	labels := make([]string, len(names))
+	for i, name := range names {
+		if e.documents[i].buffer.modified {
+			name += TABS_MODIFIED_MARKER
+		}
+		labels[i] = " " + name + " "
+	}
+
+	// the first tab is moved to the right until the current one fits
+	first := 0
+	for first < e.currentDocument {
+		used := 0
+		for i := first; i <= e.currentDocument; i++ {
+			used += len([]rune(labels[i]))
+		}
+
+		if first > 0 {
+			used += len([]rune(TABS_OVERFLOW_MARKER))
+		}
+
+		if used <= width {
+			break
+		}
+
+		first++
+	}
+
+	col := left
+	if first > 0 {
+		col += len([]rune(TABS_OVERFLOW_MARKER))
+	}
+
+	var tabs []Tab
+	for i := first; i < len(labels) && col < w; i++ {
+		tabs = append(tabs, Tab{document: i, col: col, label: labels[i]})
+		col += len([]rune(labels[i]))
+	}
+
+	return tabs
+}
+
+// get the document of the tab under the column 'col', -1 if there is none
+func (e *Editor) getTabAt(col int) int {
+	for _, tab := range e.getTabs() {
+		if col >= tab.col && col < tab.col+len([]rune(tab.label)) {
+			return tab.document
+		}
+	}
+
+	return -1
+}
+
+// move the current document 'offset' places in the tabs
+func (e *Editor) moveCurrentTab(offset int) {
+	to := e.currentDocument + offset
+	if to < 0 || to >= len(e.documents) {
+		return
+	}
+
+	e.documents[e.currentDocument], e.documents[to] = e.documents[to], e.documents[e.currentDocument]
+	e.currentDocument = to
+}
+
+// handle a click on the tabs bar: the left button selects a tab, the middle one closes it
+func (e *Editor) handleTabsMouseEvent(ev *tcell.EventMouse) bool {
+	col, row := ev.Position()
+	if row >= e.getTextAreaTop() || col < e.getTextAreaLeft() {
+		return false
+	}
+
+	index := e.getTabAt(col)
+	if index == -1 {
+		return true
+	}
+
+	switch {
+	case ev.Buttons()&tcell.Button1 != 0:
+		e.switchToDocument(index)
+	case ev.Buttons()&tcell.Button3 != 0:
+		e.closeDocument(index)
+	}
+
+	return true
+}
+
+// handle the keys moving between the tabs (Alt + Left/Right) and moving the current tab (Alt + Shift + Left/Right)
+func (e *Editor) handleTabsKeyEvent(ev *tcell.EventKey) bool {
+	if ev.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+
+	offset := 0
+	switch ev.Key() {
+	case tcell.KeyLeft:
+		offset = -1
+	case tcell.KeyRight:
+		offset = 1
+	default:
+		return false
+	}
+
+	if ev.Modifiers()&tcell.ModShift != 0 {
+		e.moveCurrentTab(offset)
+		return true
+	}
+
+	if offset < 0 {
+		e.switchToPrevDocument()
+	} else {
+		e.switchToNextDocument()
+	}
+
+	return true
+}
+
+// render the tabs of the opened documents on top of the text area
+func (e *Editor) renderTabs() {
+	w, _ := e.screen.Size()
+	barStyle := tcell.StyleDefault.Background(tcell.ColorDarkSlateGray)
+
+	for col := e.getTextAreaLeft(); col < w; col++ {
+		e.screen.SetContent(col, 0, ' ', nil, barStyle)
+	}
+
+	tabs := e.getTabs()
+	if len(tabs) > 0 && tabs[0].document > 0 {
+		e.renderTextOnStyle(0, e.getTextAreaLeft(), TABS_OVERFLOW_MARKER, barStyle)
+	}
+
+	for _, tab := range tabs {
+		style := barStyle
+		if tab.document == e.currentDocument {
+			style = tcell.StyleDefault.Bold(true).Reverse(true)
+		}
+
+		e.renderClippedTextOnStyle(0, tab.col, w-tab.col, tab.label, style)
+	}
+}
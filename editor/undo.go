@@ -0,0 +1,151 @@
+package editor
+
+const UNDO_MAX_STEPS = 100 // the oldest changes can not be undone once there are more
+
+// kinds of changes, the chars typed one after the other are undone together
+const (
+	UNDO_CHANGE = iota
+	UNDO_TYPING
+)
+
+// the content of a buffer before a change, to get it back
+type UndoStep struct {
+	lines    []Line
+	modified bool
+	cursor   Location
+}
+
+// changes undone together (a replace of all the matches, a macro...), every document gets one step for all of them
+type EditorUndoGroup struct {
+	depth int                // number of the started groups, a group can start inside another one
+	saved map[*Document]bool // documents that already got the step of the group
+}
+
+func (doc *Document) takeUndoStep(cursor Location) UndoStep {
+	return UndoStep{
+		lines:    append([]Line(nil), doc.buffer.lines...),
+		modified: doc.buffer.modified,
+		cursor:   cursor,
+	}
+}
+
+// the file was written (or read again), none of the kept contents is in it anymore
+func (doc *Document) markUndoStepsModified() {
+	for i := range doc.undo {
+		doc.undo[i].modified = true
+	}
+
+	for i := range doc.redo {
+		doc.redo[i].modified = true
+	}
+}
+
+// keep the content of the current document before a change of the kind 'kind'
+func (e *Editor) saveUndoStep(kind int) {
+	doc := e.getCurrentDocument()
+
+	if e.undoGroup.depth > 0 {
+		if e.undoGroup.saved[doc] {
+			return
+		}
+		e.undoGroup.saved[doc] = true
+	} else if kind == UNDO_TYPING && doc.typing && doc.typingEnd == e.realCursor {
+		return
+	}
+
+	doc.undo = append(doc.undo, doc.takeUndoStep(e.realCursor))
+	if len(doc.undo) > UNDO_MAX_STEPS {
+		doc.undo = doc.undo[1:]
+	}
+
+	doc.redo = nil
+	doc.typing = kind == UNDO_TYPING && e.undoGroup.depth == 0
+}
+
+// keep where the typing stopped, the next char typed there is undone with the previous ones
+func (e *Editor) markTypingEnd() {
+	e.getCurrentDocument().typingEnd = e.realCursor
+}
+
+func (e *Editor) beginUndoGroup() {
+	if e.undoGroup.depth == 0 {
+		e.undoGroup.saved = make(map[*Document]bool)
+	}
+
+	e.undoGroup.depth++
+}
+
+func (e *Editor) endUndoGroup() {
+	e.undoGroup.depth--
+}
+
+// end the group and undo the changes made since it started, in every document
+func (e *Editor) cancelUndoGroup() {
+	e.endUndoGroup()
+
+	for doc := range e.undoGroup.saved {
+		if len(doc.undo) == 0 {
+			continue
+		}
+
+		e.restoreUndoStep(doc, doc.undo[len(doc.undo)-1])
+		doc.undo = doc.undo[:len(doc.undo)-1]
+	}
+}
+
+// put back the content of the step into the document
+func (e *Editor) restoreUndoStep(doc *Document, step UndoStep) {
+	doc.buffer.lines = step.lines
+	doc.buffer.modified = step.modified
+	doc.typing = false
+
+	if doc == e.getCurrentDocument() {
+		e.realCursor = doc.buffer.clampLocation(step.cursor)
+	} else {
+		doc.realCursor = doc.buffer.clampLocation(step.cursor)
+	}
+}
+
+// check that the history can be moved in: a group (a macro being played) must find its own step on top to cancel it
+func (e *Editor) canUndo() bool {
+	if e.undoGroup.depth > 0 {
+		e.setMessage("the changes can not be undone while a macro is played")
+		return false
+	}
+
+	return e.canEdit()
+}
+
+// get the current document back to its content before the last change
+func (e *Editor) undo() {
+	if !e.canUndo() {
+		return
+	}
+
+	doc := e.getCurrentDocument()
+	if len(doc.undo) == 0 {
+		e.setMessage("nothing to undo")
+		return
+	}
+
+	doc.redo = append(doc.redo, doc.takeUndoStep(e.realCursor))
+	e.restoreUndoStep(doc, doc.undo[len(doc.undo)-1])
+	doc.undo = doc.undo[:len(doc.undo)-1]
+}
+
+// make again the last undone change of the current document
+func (e *Editor) redo() {
+	if !e.canUndo() {
+		return
+	}
+
+	doc := e.getCurrentDocument()
+	if len(doc.redo) == 0 {
+		e.setMessage("nothing to redo")
+		return
+	}
+
+	doc.undo = append(doc.undo, doc.takeUndoStep(e.realCursor))
+	e.restoreUndoStep(doc, doc.redo[len(doc.redo)-1])
+	doc.redo = doc.redo[:len(doc.redo)-1]
+}
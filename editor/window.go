@@ -0,0 +1,215 @@
+package editor
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// a view of a document on a part of the screen, the cursors of the current window are the ones of the editor
+type Window struct {
+	document        *Document
+	realCursor      Location
+	renderingCursor Location
+	x, y, w, h      int // area of the window on the screen, set before every rendering
+}
+
+// the windows are the leaves of a tree of splits, every other node splits its area between its two children
+type Layout struct {
+	window     *Window // nil for a split
+	vertical   bool    // the children are side by side (one above the other otherwise)
+	children   [2]*Layout
+	parent     *Layout
+	x, y, w, h int
+}
+
+func newWindow(doc *Document) *Window {
+	return &Window{
+		document:        doc,
+		realCursor:      doc.realCursor,
+		renderingCursor: doc.renderingCursor,
+	}
+}
+
+func newWindowLayout(win *Window) *Layout {
+	return &Layout{window: win}
+}
+
+// get the windows of the layout, from the top left one to the bottom right one
+func (layout *Layout) windows() []*Window {
+	if layout.window != nil {
+		return []*Window{layout.window}
+	}
+
+	return append(layout.children[0].windows(), layout.children[1].windows()...)
+}
+
+// find the leaf of the window 'win'
+func (layout *Layout) find(win *Window) *Layout {
+	if layout.window != nil {
+		if layout.window == win {
+			return layout
+		}
+		return nil
+	}
+
+	if found := layout.children[0].find(win); found != nil {
+		return found
+	}
+
+	return layout.children[1].find(win)
+}
+
+// share the area between the windows, a split keeps one column (or one row) for its separator
+func (layout *Layout) place(x, y, w, h int) {
+	layout.x, layout.y, layout.w, layout.h = x, y, w, h
+
+	if layout.window != nil {
+		layout.window.x, layout.window.y, layout.window.w, layout.window.h = x, y, w, h
+		return
+	}
+
+	if layout.vertical {
+		first := max(w-1, 0) / 2
+		layout.children[0].place(x, y, first, h)
+		layout.children[1].place(x+first+1, y, max(w-first-1, 0), h)
+		return
+	}
+
+	first := max(h-1, 0) / 2
+	layout.children[0].place(x, y, w, first)
+	layout.children[1].place(x, y+first+1, w, max(h-first-1, 0))
+}
+
+// place the windows on the area left to them by the sidebar, the tabs bar and the prompt
+func (e *Editor) placeWindows() {
+	w, h := e.screen.Size()
+	left := e.getTextAreaLeft()
+	top := e.getTextAreaTop()
+
+	e.layout.place(left, top, max(w-left, 0), max(h-top-BOTTOM_CURSOR_BOUNDS, 0))
+}
+
+// redraw the whole screen once the terminal is resized, the windows share its new size
+// and the current one scrolls to keep the cursor visible (the other ones do it when they are rendered)
+func (e *Editor) handleResize() {
+	e.screen.Sync()
+	e.placeWindows()
+	e.updateRenderingCursor()
+}
+
+// keep the cursors of the editor in the current window
+func (e *Editor) storeCursorInWindow() {
+	e.window.realCursor = e.realCursor
+	e.window.renderingCursor = e.renderingCursor
+}
+
+// move the cursor to the window 'win'
+func (e *Editor) focusWindow(win *Window) {
+	if win == e.window {
+		return
+	}
+
+	e.storeCursorInDocument()
+	e.storeCursorInWindow()
+
+	e.window = win
+	e.showDocument(e.findDocumentByPointer(win.document))
+	e.realCursor = e.buffer.clampLocation(win.realCursor)
+	e.renderingCursor = win.renderingCursor
+}
+
+func (e *Editor) focusNextWindow() {
+	windows := e.layout.windows()
+	for i, win := range windows {
+		if win == e.window {
+			e.focusWindow(windows[(i+1)%len(windows)])
+			return
+		}
+	}
+}
+
+// split the current window in two windows on the same document, the cursor goes to the new one
+func (e *Editor) splitWindow(vertical bool) {
+	e.storeCursorInWindow()
+
+	leaf := e.layout.find(e.window)
+	win := &Window{
+		document:        e.window.document,
+		realCursor:      e.realCursor,
+		renderingCursor: e.renderingCursor,
+	}
+
+	first := newWindowLayout(leaf.window)
+	second := newWindowLayout(win)
+	first.parent, second.parent = leaf, leaf
+
+	leaf.window = nil
+	leaf.vertical = vertical
+	leaf.children = [2]*Layout{first, second}
+
+	e.window = win
+}
+
+// close the current window, its area is given to the window (or the split) next to it
+func (e *Editor) closeWindow() {
+	leaf := e.layout.find(e.window)
+	if leaf.parent == nil {
+		e.setMessage("this is the last window")
+		return
+	}
+
+	split := leaf.parent
+	sibling := split.children[0]
+	if sibling == leaf {
+		sibling = split.children[1]
+	}
+
+	// the split takes the place of its remaining child
+	split.window = sibling.window
+	split.vertical = sibling.vertical
+	split.children = sibling.children
+	for _, child := range split.children {
+		if child != nil {
+			child.parent = split
+		}
+	}
+
+	e.storeCursorInDocument()
+	e.window = split.windows()[0]
+	e.showDocument(e.findDocumentByPointer(e.window.document))
+	e.realCursor = e.buffer.clampLocation(e.window.realCursor)
+	e.renderingCursor = e.window.renderingCursor
+}
+
+// make the windows showing the document 'old' show the document 'doc'
+func (e *Editor) replaceDocumentInWindows(old, doc *Document) {
+	for _, win := range e.layout.windows() {
+		if win.document == old {
+			win.document = doc
+			win.realCursor = doc.realCursor
+			win.renderingCursor = doc.renderingCursor
+		}
+	}
+}
+
+// handle the keys of the windows: Alt + V splits side by side, Alt + H one above the other,
+// Alt + O moves to the next window and Alt + Q closes the current one
+func (e *Editor) handleWindowKeyEvent(ev *tcell.EventKey) bool {
+	if ev.Modifiers()&tcell.ModAlt == 0 || ev.Key() != tcell.KeyRune {
+		return false
+	}
+
+	switch ev.Rune() {
+	case 'v', 'V':
+		e.splitWindow(true)
+	case 'h', 'H':
+		e.splitWindow(false)
+	case 'o', 'O':
+		e.focusNextWindow()
+	case 'q', 'Q':
+		e.closeWindow()
+	default:
+		return false
+	}
+
+	return true
+}
@@ -4,20 +4,79 @@ import (
     "edit/editor"
     "fmt"
     "os"
+    "path/filepath"
+    "strings"
 )
 
 func main() {
     var config editor.EditorConfiguration
+    var script string
+    var files []string
+
+    // like `view` for vim, the editor only shows the files when it is invoked as `gview`
+    if filepath.Base(os.Args[0]) == "gview" {
+        config.ReadOnly = true
+    }
 
-    // for now i can only pass the filepath as an argument
     for i := 1; i < len(os.Args); i++ {
         arg := os.Args[i]
-        switch arg {
+        switch {
+        case arg == "--hidden":
+            config.ShowHidden = true
+        case arg == "--follow":
+            config.Follow = true
+        case arg == "--readonly":
+            config.ReadOnly = true
+        case arg == "--stdout":
+            config.WriteStdout = true
+        case arg == "-":
+            config.ReadStdin = true
+        case arg == "--headless":
+            config.Headless = true
+        case arg == "--script":
+            i++
+            script = flagValue(arg, i)
+        case arg == "--record":
+            i++
+            config.Record = flagValue(arg, i)
+        case arg == "--replay":
+            i++
+            config.Replay = flagValue(arg, i)
+        case strings.HasPrefix(arg, "--sort="):
+            config.SortOrder = strings.TrimPrefix(arg, "--sort=")
+        case strings.HasPrefix(arg, "--autosave="):
+            config.Autosave = strings.TrimPrefix(arg, "--autosave=")
         default:
             config.OpenedFile = arg
+            files = append(files, arg)
         }
     }
 
+    // `geditor --script cmds.txt a.go b.go`: the commands are applied to the files without a terminal
+    if script != "" {
+        err := editor.RunScript(config, script, files)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    if config.Headless && config.Replay == "" {
+        fmt.Fprintln(os.Stderr, "--headless needs a recording to replay (--replay events.log)")
+        os.Exit(2)
+    }
+
+    // `git diff | geditor`: the piped content is opened, the keys are still read from the terminal
+    if config.OpenedFile == "" && !config.Headless && !isTerminal(os.Stdin) {
+        config.ReadStdin = true
+    }
+
+    // `geditor - | ...`: the edited content goes on through the pipeline
+    if config.ReadStdin && !isTerminal(os.Stdout) {
+        config.WriteStdout = true
+    }
+
     editor, err := editor.New(config)
     if err != nil {
         fmt.Fprintln(os.Stderr, err)
@@ -31,21 +90,48 @@ func main() {
         os.Exit(1)
     }
 
-    defer editor.Close()
-
     for editor.ShouldNotQuit() {
         ev := editor.PollEvent()
-        err = editor.HandleEvent(ev)
-        if err != nil {
-            editor.Quit()
+        editor.HandleEvent(ev)
+
+        // the screen stays as it was last shown (it is the output of the headless replays)
+        if editor.ShouldNotQuit() {
+            editor.Render()
         }
+    }
 
-        editor.Render()
+    // `geditor --headless --replay events.log file`: the screen at the end of the replay is the output
+    if config.Headless {
+        editor.WriteScreen(os.Stdout)
     }
 
+    // the output is written once the terminal is restored
+    editor.Close()
+
+    err = editor.WriteOutput(os.Stdout)
     if err != nil {
-        fmt.Println(err)
+        fmt.Fprintln(os.Stderr, err)
         os.Exit(1)
     }
 }
 
+// get the value following the flag 'flag' in the arguments, at the index 'i'
+func flagValue(flag string, i int) string {
+    if i >= len(os.Args) {
+        fmt.Fprintf(os.Stderr, "%s needs a path\n", flag)
+        os.Exit(2)
+    }
+
+    return os.Args[i]
+}
+
+// check if the file is a terminal rather than a pipe or a regular file
+func isTerminal(f *os.File) bool {
+    info, err := f.Stat()
+    if err != nil {
+        return false
+    }
+
+    return info.Mode()&os.ModeCharDevice != 0
+}
+